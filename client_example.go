@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -19,7 +20,9 @@ func httpDemo() {
 	port := "20001"
 	protocol := "http"
 
-	sr := sdk.GetSpeechRecognizer(host, port, protocol)
+	// 输出info及以上级别的日志
+	logger := common.NewStdLogger(nil, common.LevelInfo)
+	sr := sdk.GetSpeechRecognizer(host, port, protocol, sdk.WithLogger(logger))
 	// ======================================================
 	// 识别文件
 	filename := "testData/data1.wav"
@@ -114,13 +117,15 @@ func grpcDemo() {
 	// 识别一段长Wave音频序列
 	longSample := wave.GetRawSamples()
 	longSample = append(longSample, wave.GetRawSamples()...)
-	resultLong, err := sr.RecogniteLong(longSample, wave.FrameRate, wave.Channels, wave.SampleWidth)
+	// 语速超过每秒6个音节的片段在识别前放慢到每秒4个音节，可选配置项通过 ContextSpeechRecognizer 的方法传入
+	resultLong, err := sr.(sdk.ContextSpeechRecognizer).RecogniteLongContext(context.Background(),
+		longSample, wave.FrameRate, wave.Channels, wave.SampleWidth, sdk.WithSpeechRateNormalization(6, 4))
 	if err != nil {
 		fmt.Println(err)
 	}
 
 	for index, res := range resultLong {
-		fmt.Println("长文件语音识别结果 ", index, ":", res.Result)
+		fmt.Println("长文件语音识别结果 ", index, res.Segment.Start, "-", res.Segment.End, ":", res.Result)
 	}
 	// ======================================================
 	// 调用声学模型识别一段Wave音频序列
@@ -167,7 +172,10 @@ func grpcDemo() {
 	go sendFunction()
 	var asrResult string
 	var tmpAsrResult string
+	recvDone := make(chan struct{})
 	recvFunction := func() {
+		defer close(recvDone)
+		// recognitionResult 由 RecogniteStream 在结束时关闭
 		for value := range recognitionResult {
 			fmt.Println("流式解码结果：", value.StatusCode, value.Result, value.StatucMesaage)
 			if value.StatusCode == common.APIStatusCodeOK {
@@ -181,9 +189,9 @@ func grpcDemo() {
 	}
 	go recvFunction()
 	err = sr.(*sdk.GRPCSpeechRecognizer).RecogniteStream(wavChannel, recognitionResult)
+	<-recvDone
 	fmt.Println("流式识别完毕")
 	if err != nil {
 		fmt.Println(err)
 	}
-	close(recognitionResult)
 }
@@ -0,0 +1,322 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Augmentation 音频数据增强操作，用于模拟各种劣化的输入以测试识别的鲁棒性
+type Augmentation interface {
+	// Apply 对音频做数据增强并返回新的FloatBuffer对象，rng 为该操作使用的随机数生成器
+	Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error)
+}
+
+// Augment 依次对音频应用各个数据增强操作
+//
+// 第i个操作使用以 seed+i 为种子的随机数生成器，因此相同的 seed 总能得到相同的结果
+func (f *FloatBuffer) Augment(seed int64, augmentations ...Augmentation) (*FloatBuffer, error) {
+	buffer := f
+	for i, augmentation := range augmentations {
+		rng := rand.New(rand.NewSource(seed + int64(i)))
+		augmented, err := augmentation.Apply(buffer, rng)
+		if err != nil {
+			return nil, err
+		}
+		buffer = augmented
+	}
+
+	if buffer == f {
+		return f.Clone(), nil
+	}
+
+	return buffer, nil
+}
+
+// Augment 依次对Wav对象应用各个数据增强操作，参见 FloatBuffer.Augment
+func (w *Wav) Augment(seed int64, augmentations ...Augmentation) (Wav, error) {
+	buffer, err := w.ToFloatBuffer()
+	if err != nil {
+		return Wav{}, err
+	}
+
+	augmented, err := buffer.Augment(seed, augmentations...)
+	if err != nil {
+		return Wav{}, err
+	}
+
+	return augmented.ToWav(), nil
+}
+
+// NoiseType 噪声类型
+type NoiseType int
+
+const (
+	// NoiseWhite 白噪声
+	NoiseWhite NoiseType = iota
+	// NoisePink 粉红噪声
+	NoisePink
+)
+
+// AdditiveNoise 按目标信噪比叠加噪声
+type AdditiveNoise struct {
+	// SNR 目标信噪比，单位：dB
+	SNR float64
+	// Type 生成噪声的类型，Noise 不为nil时忽略
+	Type NoiseType
+	// Noise 作为噪声源的音频，为nil时使用 Type 生成噪声。
+	// 噪声音频会被混合为单声道、重采样并循环到所需长度，起始位置随机
+	Noise *Wav
+}
+
+// Apply 实现 Augmentation 接口
+func (a AdditiveNoise) Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error) {
+	numFrames := buffer.NumFrames()
+	var noise []float32
+	if a.Noise != nil {
+		noiseBuffer, err := a.Noise.ToFloatBuffer()
+		if err != nil {
+			return nil, err
+		}
+		noiseBuffer, err = noiseBuffer.MixToMono().Resample(buffer.FrameRate)
+		if err != nil {
+			return nil, err
+		}
+		noise = loopSamples(noiseBuffer.Samples[0], numFrames, rng)
+	} else {
+		switch a.Type {
+		case NoiseWhite:
+			noise = whiteNoise(numFrames, rng)
+		case NoisePink:
+			noise = pinkNoise(numFrames, rng)
+		default:
+			return nil, fmt.Errorf("error: unsupport noise type `%d`", a.Type)
+		}
+	}
+
+	output := buffer.Clone()
+	signalPower := meanSquare(buffer.Samples...)
+	noisePower := meanSquare(noise)
+	if signalPower == 0 || noisePower == 0 {
+		return output, nil
+	}
+
+	gain := float32(math.Sqrt(signalPower / (noisePower * math.Pow(10, a.SNR/10))))
+	for _, samples := range output.Samples {
+		for n := range samples {
+			samples[n] += gain * noise[n]
+		}
+	}
+
+	return output, nil
+}
+
+// Reverb 与房间冲激响应做卷积以模拟混响，输出保持原有时长与能量
+type Reverb struct {
+	// ImpulseResponse 房间冲激响应，会被混合为单声道并重采样到与输入一致的采样频率
+	ImpulseResponse Wav
+}
+
+// Apply 实现 Augmentation 接口
+func (a Reverb) Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error) {
+	ir, err := a.ImpulseResponse.ToFloatBuffer()
+	if err != nil {
+		return nil, err
+	}
+	ir, err = ir.MixToMono().Resample(buffer.FrameRate)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &FloatBuffer{
+		Samples:   make([][]float32, buffer.Channels()),
+		FrameRate: buffer.FrameRate,
+	}
+	for i, samples := range buffer.Samples {
+		wet := convolve(samples, ir.Samples[0])
+		// 保持卷积前后能量一致
+		wetPower := meanSquare(wet)
+		if wetPower > 0 {
+			gain := float32(math.Sqrt(meanSquare(samples) / wetPower))
+			for n := range wet {
+				wet[n] *= gain
+			}
+		}
+		output.Samples[i] = wet
+	}
+
+	return output, nil
+}
+
+// SpeedPerturbation 语速扰动，同时改变语速和音高，相当于以不同的速度播放
+type SpeedPerturbation struct {
+	// MinFactor 最小速度倍率，例如0.9
+	MinFactor float64
+	// MaxFactor 最大速度倍率，例如1.1，实际倍率在[MinFactor, MaxFactor]中均匀随机选取
+	MaxFactor float64
+}
+
+// Apply 实现 Augmentation 接口
+func (a SpeedPerturbation) Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error) {
+	if a.MinFactor <= 0 || a.MaxFactor < a.MinFactor {
+		return nil, fmt.Errorf("error: invalid speed perturbation factor range [%f, %f]", a.MinFactor, a.MaxFactor)
+	}
+
+	factor := a.MinFactor + rng.Float64()*(a.MaxFactor-a.MinFactor)
+	return buffer.resampleRatio(1 / factor), nil
+}
+
+// BandLimit 带通限制，用于模拟电话等窄带信道
+type BandLimit struct {
+	// LowCutoff 高通截止频率，单位：Hz，为0时不做高通滤波
+	LowCutoff float64
+	// HighCutoff 低通截止频率，单位：Hz，为0时不做低通滤波
+	HighCutoff float64
+	// NarrowbandRate 不为0时先降采样到该频率再升采样回原频率，以模拟窄带编码
+	NarrowbandRate int
+}
+
+// TelephoneBandLimit 模拟电话信道的带通限制：300Hz-3400Hz，8kHz窄带采样
+func TelephoneBandLimit() BandLimit {
+	return BandLimit{
+		LowCutoff:      300,
+		HighCutoff:     3400,
+		NarrowbandRate: 8000,
+	}
+}
+
+// Apply 实现 Augmentation 接口
+func (a BandLimit) Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error) {
+	output := buffer
+	var err error
+	if a.NarrowbandRate > 0 && a.NarrowbandRate < buffer.FrameRate {
+		output, err = output.Resample(a.NarrowbandRate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.LowCutoff > 0 {
+		output = output.HighPass(a.LowCutoff)
+	}
+	if a.HighCutoff > 0 && a.HighCutoff < float64(output.FrameRate)/2 {
+		output = output.LowPass(a.HighCutoff)
+	}
+
+	if output.FrameRate != buffer.FrameRate {
+		output, err = output.Resample(buffer.FrameRate)
+		if err != nil {
+			return nil, err
+		}
+		// 重采样的舍入可能使长度相差一个采样点，保持与输入等长
+		output = padOrTrim(output, buffer.NumFrames())
+	}
+
+	if output == buffer {
+		return buffer.Clone(), nil
+	}
+
+	return output, nil
+}
+
+// RandomGain 随机增益
+type RandomGain struct {
+	// MinGainDB 最小增益，单位：dB
+	MinGainDB float64
+	// MaxGainDB 最大增益，单位：dB，实际增益在[MinGainDB, MaxGainDB]中均匀随机选取
+	MaxGainDB float64
+}
+
+// Apply 实现 Augmentation 接口
+func (a RandomGain) Apply(buffer *FloatBuffer, rng *rand.Rand) (*FloatBuffer, error) {
+	if a.MaxGainDB < a.MinGainDB {
+		return nil, fmt.Errorf("error: invalid gain range [%f, %f]", a.MinGainDB, a.MaxGainDB)
+	}
+
+	gainDB := a.MinGainDB + rng.Float64()*(a.MaxGainDB-a.MinGainDB)
+	gain := float32(math.Pow(10, gainDB/20))
+	output := buffer.Clone()
+	for _, samples := range output.Samples {
+		for n := range samples {
+			samples[n] *= gain
+		}
+	}
+
+	return output, nil
+}
+
+// whiteNoise 生成标准差为1的高斯白噪声
+func whiteNoise(numFrames int, rng *rand.Rand) []float32 {
+	noise := make([]float32, numFrames)
+	for n := range noise {
+		noise[n] = float32(rng.NormFloat64())
+	}
+
+	return noise
+}
+
+// pinkNoise 使用Paul Kellett的滤波方法由白噪声生成粉红噪声
+func pinkNoise(numFrames int, rng *rand.Rand) []float32 {
+	noise := make([]float32, numFrames)
+	var b0, b1, b2, b3, b4, b5, b6 float64
+	for n := range noise {
+		white := rng.NormFloat64()
+		b0 = 0.99886*b0 + white*0.0555179
+		b1 = 0.99332*b1 + white*0.0750759
+		b2 = 0.96900*b2 + white*0.1538520
+		b3 = 0.86650*b3 + white*0.3104856
+		b4 = 0.55000*b4 + white*0.5329522
+		b5 = -0.7616*b5 - white*0.0168980
+		noise[n] = float32((b0 + b1 + b2 + b3 + b4 + b5 + b6 + white*0.5362) * 0.11)
+		b6 = white * 0.115926
+	}
+
+	return noise
+}
+
+// loopSamples 从随机起始位置开始循环读取 source，得到长度为 length 的序列
+func loopSamples(source []float32, length int, rng *rand.Rand) []float32 {
+	output := make([]float32, length)
+	if len(source) == 0 {
+		return output
+	}
+
+	offset := rng.Intn(len(source))
+	for n := range output {
+		output[n] = source[(offset+n)%len(source)]
+	}
+
+	return output
+}
+
+// padOrTrim 将音频补零或截断到指定长度
+func padOrTrim(buffer *FloatBuffer, numFrames int) *FloatBuffer {
+	if buffer.NumFrames() == numFrames {
+		return buffer
+	}
+
+	output := NewFloatBuffer(buffer.FrameRate, buffer.Channels(), numFrames)
+	for i, samples := range buffer.Samples {
+		copy(output.Samples[i], samples)
+	}
+
+	return output
+}
+
+// meanSquare 计算所有采样值的均方值
+func meanSquare(channels ...[]float32) float64 {
+	var sum float64
+	count := 0
+	for _, samples := range channels {
+		for _, value := range samples {
+			sum += float64(value) * float64(value)
+		}
+		count += len(samples)
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return sum / float64(count)
+}
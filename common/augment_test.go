@@ -0,0 +1,151 @@
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitAugment(t *testing.T) {
+	suite.Run(t, new(TestUnitAugmentSuite))
+}
+
+type TestUnitAugmentSuite struct {
+	suite.Suite
+	wave Wav
+}
+
+func (t *TestUnitAugmentSuite) SetupTest() {
+	t.wave = fixtureWav()
+}
+
+// sineBuffer 生成指定频率的正弦波
+func sineBuffer(frequency float64, numFrames int) *FloatBuffer {
+	buffer := NewFloatBuffer(16000, 1, numFrames)
+	for n := range buffer.Samples[0] {
+		buffer.Samples[0][n] = float32(0.5 * math.Sin(2*math.Pi*frequency*float64(n)/16000))
+	}
+
+	return buffer
+}
+
+func (t *TestUnitAugmentSuite) TestReproducible() {
+	augmentations := []Augmentation{
+		AdditiveNoise{SNR: 10, Type: NoisePink},
+		SpeedPerturbation{MinFactor: 0.9, MaxFactor: 1.1},
+		RandomGain{MinGainDB: -6, MaxGainDB: 6},
+		TelephoneBandLimit(),
+	}
+
+	wave1, err := t.wave.Augment(42, augmentations...)
+	t.Nil(err)
+	wave2, err := t.wave.Augment(42, augmentations...)
+	t.Nil(err)
+	wave3, err := t.wave.Augment(43, augmentations...)
+	t.Nil(err)
+
+	t.Equal(wave1.GetRawSamples(), wave2.GetRawSamples())
+	t.NotEqual(wave1.GetRawSamples(), wave3.GetRawSamples())
+}
+
+func (t *TestUnitAugmentSuite) TestAdditiveNoise() {
+	buffer := sineBuffer(440, 16000)
+	for _, noiseType := range []NoiseType{NoiseWhite, NoisePink} {
+		noisy, err := buffer.Augment(1, AdditiveNoise{SNR: 10, Type: noiseType})
+		t.Nil(err)
+
+		noise := make([]float32, buffer.NumFrames())
+		for n := range noise {
+			noise[n] = noisy.Samples[0][n] - buffer.Samples[0][n]
+		}
+		snr := 10 * math.Log10(meanSquare(buffer.Samples[0])/meanSquare(noise))
+		t.InDelta(10, snr, 0.01)
+	}
+
+	noiseWave := t.wave
+	noisy, err := buffer.Augment(1, AdditiveNoise{SNR: 0, Noise: &noiseWave})
+	t.Nil(err)
+	t.Equal(buffer.NumFrames(), noisy.NumFrames())
+
+	_, err = buffer.Augment(1, AdditiveNoise{SNR: 0, Type: NoiseType(100)})
+	t.NotNil(err)
+}
+
+func (t *TestUnitAugmentSuite) TestReverb() {
+	ir := NewFloatBuffer(16000, 1, 4000)
+	for n := range ir.Samples[0] {
+		ir.Samples[0][n] = float32(math.Exp(-float64(n)/800) * math.Cos(float64(n)))
+	}
+
+	buffer, err := t.wave.ToFloatBuffer()
+	t.Nil(err)
+	reverbed, err := buffer.Augment(1, Reverb{ImpulseResponse: ir.ToWav()})
+	t.Nil(err)
+	t.Equal(buffer.NumFrames(), reverbed.NumFrames())
+	t.InDelta(meanSquare(buffer.Samples[0]), meanSquare(reverbed.Samples[0]), 1e-6)
+
+	// 单位冲激响应不改变信号
+	impulse := NewFloatBuffer(16000, 1, 1)
+	impulse.Samples[0][0] = 0.5
+	identity, err := buffer.Augment(1, Reverb{ImpulseResponse: impulse.ToWav()})
+	t.Nil(err)
+	for n := 0; n < 1000; n += 1 {
+		t.InDelta(buffer.Samples[0][n], identity.Samples[0][n], 1e-4)
+	}
+}
+
+func (t *TestUnitAugmentSuite) TestSpeedPerturbation() {
+	buffer := sineBuffer(440, 16000)
+	faster, err := buffer.Augment(1, SpeedPerturbation{MinFactor: 2, MaxFactor: 2})
+	t.Nil(err)
+	t.Equal(8000, faster.NumFrames())
+
+	_, err = buffer.Augment(1, SpeedPerturbation{MinFactor: 0, MaxFactor: 1})
+	t.NotNil(err)
+}
+
+func (t *TestUnitAugmentSuite) TestBandLimit() {
+	for _, tt := range []struct {
+		frequency float64
+		pass      bool
+	}{
+		{frequency: 100, pass: false},
+		{frequency: 1000, pass: true},
+		{frequency: 6000, pass: false},
+	} {
+		buffer := sineBuffer(tt.frequency, 16000)
+		limited, err := buffer.Augment(1, TelephoneBandLimit())
+		t.Nil(err)
+		t.Equal(buffer.NumFrames(), limited.NumFrames())
+
+		ratio := meanSquare(limited.Samples[0][4000:12000]) / meanSquare(buffer.Samples[0][4000:12000])
+		if tt.pass {
+			t.InDelta(1, ratio, 0.1)
+		} else {
+			t.Less(ratio, 0.05)
+		}
+	}
+}
+
+func (t *TestUnitAugmentSuite) TestRandomGain() {
+	buffer := sineBuffer(440, 1600)
+	louder, err := buffer.Augment(1, RandomGain{MinGainDB: 6, MaxGainDB: 6})
+	t.Nil(err)
+	t.InDelta(buffer.Samples[0][10]*float32(math.Pow(10, 0.3)), louder.Samples[0][10], 1e-6)
+
+	_, err = buffer.Augment(1, RandomGain{MinGainDB: 6, MaxGainDB: 0})
+	t.NotNil(err)
+}
+
+func (t *TestUnitAugmentSuite) TestResample() {
+	buffer := sineBuffer(440, 16000)
+	resampled, err := buffer.Resample(8000)
+	t.Nil(err)
+	t.Equal(8000, resampled.FrameRate)
+	t.Equal(8000, resampled.NumFrames())
+	for n := 1000; n < 1100; n += 1 {
+		expected := 0.5 * math.Sin(2*math.Pi*440*float64(n)/8000)
+		t.InDelta(expected, resampled.Samples[0][n], 0.01)
+	}
+}
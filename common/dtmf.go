@@ -0,0 +1,187 @@
+package common
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// dtmfBlockDuration Goertzel算法每个分析块的时长，单位：秒
+	dtmfBlockDuration = 0.02
+	// dtmfHopDuration 相邻两个分析块的间隔，单位：秒
+	dtmfHopDuration = 0.01
+	// dtmfMinBlocks 判定为一个按键音所需的最少连续分析块数，对应约40ms的最短按键时长
+	dtmfMinBlocks = 3
+	// dtmfMinToneRatio 行频率与列频率能量之和占分析块总能量的最小比例
+	dtmfMinToneRatio = 0.6
+	// dtmfMaxTwist 行频率与列频率能量之比的最大偏差，约为8dB
+	dtmfMaxTwist = 6.3
+	// dtmfMinPeakRatio 同组中最强频率与次强频率能量之比的最小值，约为6dB
+	dtmfMinPeakRatio = 4.0
+	// dtmfMinBlockPower 分析块的最小平均功率，约为-60dBFS，用于排除静音段
+	dtmfMinBlockPower = 1e-6
+)
+
+// DTMFTone 检测到的DTMF按键音
+type DTMFTone struct {
+	// Digit 按键字符，为 0-9、*、#、A-D 之一
+	Digit rune
+	// Start 按键音的起始时间
+	Start time.Duration
+	// End 按键音的结束时间
+	End time.Duration
+}
+
+// DetectDTMF 使用Goertzel算法检测音频中的DTMF按键音，返回按时间排序的按键序列
+func (f *FloatBuffer) DetectDTMF() []DTMFTone {
+	if f.FrameRate <= 0 || f.NumFrames() == 0 {
+		return nil
+	}
+
+	samples := f.MixToMono().Samples[0]
+	blockLen := int(float64(f.FrameRate) * dtmfBlockDuration)
+	hop := int(float64(f.FrameRate) * dtmfHopDuration)
+	if blockLen <= 0 || hop <= 0 {
+		return nil
+	}
+
+	var tones []DTMFTone
+	var current rune
+	runStart := 0
+	runLength := 0
+	flush := func(end int) {
+		if current != 0 && runLength >= dtmfMinBlocks {
+			tones = append(tones, DTMFTone{
+				Digit: current,
+				Start: FramesToDuration(runStart, f.FrameRate),
+				End:   FramesToDuration(end, f.FrameRate),
+			})
+		}
+	}
+
+	lastEnd := 0
+	for start := 0; start+blockLen <= len(samples); start += hop {
+		digit := detectDTMFBlock(samples[start:start+blockLen], f.FrameRate)
+		if digit != current {
+			flush(lastEnd)
+			current = digit
+			runStart = start
+			runLength = 0
+		}
+		if digit != 0 {
+			runLength += 1
+			lastEnd = start + blockLen
+		}
+	}
+	flush(lastEnd)
+
+	return tones
+}
+
+// DetectDTMF 检测Wav对象中的DTMF按键音，参见 FloatBuffer.DetectDTMF
+func (w *Wav) DetectDTMF() ([]DTMFTone, error) {
+	buffer, err := w.ToFloatBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.DetectDTMF(), nil
+}
+
+// MaskDTMF 检测Wav对象中的DTMF按键音，并将按键音所在区间替换为静音，
+// 返回处理后的新Wav对象和检测到的按键序列
+func (w *Wav) MaskDTMF() (Wav, []DTMFTone, error) {
+	tones, err := w.DetectDTMF()
+	if err != nil {
+		return Wav{}, nil, err
+	}
+
+	masked, err := NewWavFromRawSamples(append([]byte(nil), w.data...), w.FrameRate, w.Channels, w.SampleWidth)
+	if err != nil {
+		return Wav{}, nil, err
+	}
+	for _, tone := range tones {
+		masked.Mute(tone.Start, tone.End)
+	}
+
+	return masked, tones, nil
+}
+
+// Mute 将 [start, end) 时间范围内的采样数据置为静音
+func (w *Wav) Mute(start time.Duration, end time.Duration) {
+	blockAlign := w.blockAlign()
+	first := durationToFrames(start, w.FrameRate) * blockAlign
+	last := durationToFrames(end, w.FrameRate) * blockAlign
+	if last > len(w.data) {
+		last = len(w.data)
+	}
+
+	for p := first; p < last; p += 1 {
+		w.data[p] = 0
+	}
+}
+
+// detectDTMFBlock 检测一个分析块中的按键，未检测到时返回0
+func detectDTMFBlock(block []float32, frameRate int) rune {
+	var energy float64
+	for _, value := range block {
+		energy += float64(value) * float64(value)
+	}
+	if energy/float64(len(block)) < dtmfMinBlockPower {
+		return 0
+	}
+
+	// 将Goertzel能量归一化为该频率成分占分析块总能量的比例，单一正弦波的比例约为1
+	scale := 2 / (float64(len(block)) * energy)
+	var rowPowers, columnPowers [4]float64
+	for i := 0; i < 4; i += 1 {
+		rowPowers[i] = goertzelPower(block, dtmfRowFrequencies[i], frameRate) * scale
+		columnPowers[i] = goertzelPower(block, dtmfColumnFrequencies[i], frameRate) * scale
+	}
+
+	row, rowPower, ok := dominantFrequency(rowPowers)
+	if !ok {
+		return 0
+	}
+	column, columnPower, ok := dominantFrequency(columnPowers)
+	if !ok {
+		return 0
+	}
+
+	if rowPower+columnPower < dtmfMinToneRatio {
+		return 0
+	}
+	if rowPower > columnPower*dtmfMaxTwist || columnPower > rowPower*dtmfMaxTwist {
+		return 0
+	}
+
+	return dtmfKeypad[row][column]
+}
+
+// dominantFrequency 找出同组中能量最强的频率，并要求其明显强于次强的频率
+func dominantFrequency(powers [4]float64) (index int, power float64, ok bool) {
+	second := 0.0
+	for i, value := range powers {
+		if value > power {
+			second = power
+			index = i
+			power = value
+		} else if value > second {
+			second = value
+		}
+	}
+
+	return index, power, power > second*dtmfMinPeakRatio
+}
+
+// goertzelPower 使用Goertzel算法计算序列在指定频率上的能量
+func goertzelPower(block []float32, frequency float64, frameRate int) float64 {
+	coefficient := 2 * math.Cos(2*math.Pi*frequency/float64(frameRate))
+	var s1, s2 float64
+	for _, value := range block {
+		s0 := float64(value) + coefficient*s1 - s2
+		s2, s1 = s1, s0
+	}
+
+	return s1*s1 + s2*s2 - coefficient*s1*s2
+}
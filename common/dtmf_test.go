@@ -0,0 +1,73 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitDTMF(t *testing.T) {
+	suite.Run(t, new(TestUnitDTMFSuite))
+}
+
+type TestUnitDTMFSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitDTMFSuite) TestDetectDTMF() {
+	digits := "0123456789*#ABCD"
+	for _, frameRate := range []int{8000, 16000} {
+		wave, err := GenerateDTMF(digits, 80*time.Millisecond, 60*time.Millisecond, 0.5, frameRate, 1)
+		t.Nil(err)
+
+		// 叠加噪声，检测结果不应受影响
+		wave, err = wave.Augment(1, AdditiveNoise{SNR: 20, Type: NoiseWhite})
+		t.Nil(err)
+
+		tones, err := wave.DetectDTMF()
+		t.Nil(err)
+		t.Equal(len(digits), len(tones))
+		for i, tone := range tones {
+			t.Equal(rune(digits[i]), tone.Digit)
+
+			expectedStart := time.Duration(i) * 140 * time.Millisecond
+			t.InDelta(float64(expectedStart), float64(tone.Start), float64(15*time.Millisecond))
+			t.InDelta(float64(expectedStart+80*time.Millisecond), float64(tone.End), float64(15*time.Millisecond))
+		}
+	}
+}
+
+func (t *TestUnitDTMFSuite) TestNoFalsePositive() {
+	wave := fixtureWav()
+	tones, err := wave.DetectDTMF()
+	t.Nil(err)
+	t.Equal(0, len(tones))
+
+	sine := GenerateSine(1209, 0.5, time.Second, 8000, 1)
+	tones, err = sine.DetectDTMF()
+	t.Nil(err)
+	t.Equal(0, len(tones))
+}
+
+func (t *TestUnitDTMFSuite) TestMaskDTMF() {
+	speech := fixtureWav()
+	tones, err := GenerateDTMF("5", 100*time.Millisecond, 0, 0.5, 16000, 1)
+	t.Nil(err)
+	wave := NewBlankWav(16000, 1, 2)
+	t.Nil(wave.AppendWav(tones))
+	t.Nil(wave.AppendWav(speech))
+
+	masked, detected, err := wave.MaskDTMF()
+	t.Nil(err)
+	t.Equal(1, len(detected))
+	t.Equal('5', detected[0].Digit)
+	t.Equal(wave.NumFrames(), masked.NumFrames())
+
+	for n := 0; n < 1500; n += 1 {
+		t.Equal(int16(0), masked.Sample(0, n))
+	}
+	// 语音部分保持不变，原对象也不受影响
+	t.Equal(wave.GetRawSamples()[4000:], masked.GetRawSamples()[4000:])
+	t.NotEqual(int16(0), wave.Sample(0, 100))
+}
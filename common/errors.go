@@ -0,0 +1,163 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ASRT语音识别SDK的错误分类，可以使用 errors.Is 判断错误属于哪一类，
+// 使用 errors.As 获取 *APIError 或 *TransportError 以读取详细信息
+var (
+	// ErrClient 客户端请求错误，是 ErrClientFormat 和 ErrUnsupportedConfig 的上级分类
+	ErrClient = errors.New("asrt client error")
+	// ErrClientFormat 请求数据格式错误，属于 ErrClient 分类
+	ErrClientFormat = newCategoryError("asrt request data format error", ErrClient)
+	// ErrUnsupportedConfig 请求数据配置不支持，例如不支持的采样率或声道数，属于 ErrClient 分类
+	ErrUnsupportedConfig = newCategoryError("asrt request config not supported", ErrClient)
+	// ErrServer 服务端运行出错
+	ErrServer = errors.New("asrt server error")
+	// ErrTransport 网络传输出错，例如连接失败、超时或连接中断
+	ErrTransport = errors.New("asrt transport error")
+	// ErrPartialResult 服务端只返回了部分识别结果，此时响应对象仍然可用
+	ErrPartialResult = errors.New("asrt partial result")
+	// ErrAuth 获取认证信息失败，请求未发送到服务端
+	ErrAuth = errors.New("asrt authentication error")
+	// ErrCircuitOpen 熔断器处于打开状态，请求未发送到服务端即被拒绝
+	ErrCircuitOpen = errors.New("asrt circuit breaker is open")
+)
+
+// categoryError 带有上级分类的错误分类，errors.Is 判断上级分类时同样成立
+type categoryError struct {
+	message string
+	parent  error
+}
+
+// newCategoryError 创建属于 parent 分类的错误分类
+func newCategoryError(message string, parent error) error {
+	return &categoryError{message: message, parent: parent}
+}
+
+// Error 实现 error 接口
+func (e *categoryError) Error() string {
+	return e.message
+}
+
+// Unwrap 获取上级分类
+func (e *categoryError) Unwrap() error {
+	return e.parent
+}
+
+// APIError ASRT语音识别接口返回的非成功状态
+type APIError struct {
+	// StatusCode ASRT接口状态码，例如 APIStatusCodeClientErrorFormat
+	StatusCode int
+	// Message 状态信息
+	Message string
+	// HTTPStatus HTTP响应状态码，非HTTP协议时为0
+	HTTPStatus int
+	// GRPCCode gRPC状态码，非gRPC协议或服务端正常返回时为 codes.OK
+	GRPCCode codes.Code
+	// Response 服务端返回的原始响应，可能为nil
+	Response *AsrtAPIResponse
+	// RequestID 出错的请求的ID，用于与服务端日志关联
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error: asrt api status code `%d`, %s%s", e.StatusCode, e.Message, requestIDSuffix(e.RequestID))
+}
+
+// Is 按照ASRT接口状态码将错误归入对应的分类
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrPartialResult:
+		return e.StatusCode == APIStatusCodePartOK
+	case ErrClient:
+		return e.StatusCode/100000 == APIStatusCodeClientError/100000
+	case ErrClientFormat:
+		return e.StatusCode == APIStatusCodeClientErrorFormat
+	case ErrUnsupportedConfig:
+		return e.StatusCode == APIStatusCodeClientErrorConfig
+	case ErrServer:
+		return e.StatusCode/100000 == APIStatusCodeServerError/100000
+	}
+
+	return false
+}
+
+// TransportError 网络传输过程中发生的错误
+type TransportError struct {
+	// Op 出错时正在进行的操作，例如 "all"、"speech"、"language"
+	Op string
+	// Err 底层错误
+	Err error
+	// RequestID 出错的请求的ID，用于与服务端日志关联
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("error: asrt transport failed when calling `%s`, %s%s", e.Op, e.Err, requestIDSuffix(e.RequestID))
+}
+
+// requestIDSuffix 获取错误信息中请求ID的部分，未设置请求ID时为空
+func requestIDSuffix(requestID string) string {
+	if requestID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (request id `%s`)", requestID)
+}
+
+// Unwrap 获取底层错误
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is 传输错误都属于 ErrTransport 分类
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport
+}
+
+// CheckAPIResponse 检查ASRT接口响应的状态码，非成功状态时返回 *APIError，
+// 部分识别结果返回的错误属于 ErrPartialResult 分类
+func CheckAPIResponse(response *AsrtAPIResponse) error {
+	if response.StatusCode == APIStatusCodeOK {
+		return nil
+	}
+
+	return &APIError{
+		StatusCode: response.StatusCode,
+		Message:    response.StatucMesaage,
+		Response:   response,
+	}
+}
+
+// NewHTTPStatusError 根据非200的HTTP响应状态构造 *APIError，
+// body 能够解析为ASRT接口响应时使用其中的状态码，否则按HTTP状态码归类
+func NewHTTPStatusError(httpStatus int, response *AsrtAPIResponse) *APIError {
+	if response != nil && response.StatusCode != 0 && response.StatusCode != APIStatusCodeOK {
+		return &APIError{
+			StatusCode: response.StatusCode,
+			Message:    response.StatucMesaage,
+			HTTPStatus: httpStatus,
+			Response:   response,
+		}
+	}
+
+	statusCode := APIStatusCodeServerError
+	if httpStatus >= 400 && httpStatus < 500 {
+		statusCode = APIStatusCodeClientError
+	}
+
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    fmt.Sprintf("http status %d %s", httpStatus, http.StatusText(httpStatus)),
+		HTTPStatus: httpStatus,
+		Response:   response,
+	}
+}
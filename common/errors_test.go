@@ -0,0 +1,112 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitErrors(t *testing.T) {
+	suite.Run(t, new(TestUnitErrorsSuite))
+}
+
+type TestUnitErrorsSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitErrorsSuite) TestCheckAPIResponse() {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       []error
+		notWant    []error
+	}{
+		{
+			name:       "ok",
+			statusCode: APIStatusCodeOK,
+		},
+		{
+			name:       "part ok",
+			statusCode: APIStatusCodePartOK,
+			want:       []error{ErrPartialResult},
+			notWant:    []error{ErrClient, ErrServer, ErrTransport},
+		},
+		{
+			name:       "format",
+			statusCode: APIStatusCodeClientErrorFormat,
+			want:       []error{ErrClient, ErrClientFormat},
+			notWant:    []error{ErrUnsupportedConfig, ErrServer},
+		},
+		{
+			name:       "config",
+			statusCode: APIStatusCodeClientErrorConfig,
+			want:       []error{ErrClient, ErrUnsupportedConfig},
+			notWant:    []error{ErrClientFormat, ErrServer},
+		},
+		{
+			name:       "server",
+			statusCode: APIStatusCodeServerErrorRunning,
+			want:       []error{ErrServer},
+			notWant:    []error{ErrClient, ErrTransport, ErrPartialResult},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			response := &AsrtAPIResponse{StatusCode: tt.statusCode, StatucMesaage: tt.name}
+			err := CheckAPIResponse(response)
+			if tt.want == nil {
+				t.Nil(err)
+				return
+			}
+
+			// 包装后仍然可以判断分类
+			wrapped := fmt.Errorf("wrapped: %w", err)
+			for _, target := range tt.want {
+				t.True(errors.Is(wrapped, target), target.Error())
+			}
+			for _, target := range tt.notWant {
+				t.False(errors.Is(wrapped, target), target.Error())
+			}
+
+			var apiErr *APIError
+			t.True(errors.As(wrapped, &apiErr))
+			t.Equal(tt.statusCode, apiErr.StatusCode)
+			t.Equal(response, apiErr.Response)
+		})
+	}
+}
+
+func (t *TestUnitErrorsSuite) TestHTTPStatusError() {
+	err := NewHTTPStatusError(503, nil)
+	t.True(errors.Is(err, ErrServer))
+	t.Equal(503, err.HTTPStatus)
+
+	err = NewHTTPStatusError(404, nil)
+	t.True(errors.Is(err, ErrClient))
+
+	err = NewHTTPStatusError(400, &AsrtAPIResponse{StatusCode: APIStatusCodeClientErrorConfig})
+	t.True(errors.Is(err, ErrUnsupportedConfig))
+	t.Equal(400, err.HTTPStatus)
+}
+
+func (t *TestUnitErrorsSuite) TestTransportError() {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("wrapped: %w", &TransportError{Op: "all", Err: cause})
+	t.True(errors.Is(err, ErrTransport))
+	t.True(errors.Is(err, cause))
+	t.False(errors.Is(err, ErrServer))
+}
+
+func (t *TestUnitErrorsSuite) TestCategoryHierarchy() {
+	for _, category := range []error{ErrClientFormat, ErrUnsupportedConfig} {
+		err := fmt.Errorf("error: wrapped: %w", category)
+		t.True(errors.Is(err, category))
+		t.True(errors.Is(err, ErrClient))
+		t.False(errors.Is(err, ErrServer))
+	}
+	t.False(errors.Is(ErrClient, ErrClientFormat))
+	t.False(errors.Is(ErrClientFormat, ErrUnsupportedConfig))
+}
@@ -0,0 +1,105 @@
+package common
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// nextPowerOfTwo 获取不小于 n 的最小的2的整数次幂
+func nextPowerOfTwo(n int) int {
+	size := 1
+	for size < n {
+		size <<= 1
+	}
+
+	return size
+}
+
+// fft 原地计算基2快速傅里叶变换，inverse 为true时计算逆变换(含1/N归一化)，
+// 输入长度必须为2的整数次幂
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// 位反转重排
+	for i, j := 1, 0; i < n; i += 1 {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k += 1 {
+				even := x[start+k]
+				odd := x[start+k+size/2] * w
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
+
+// convolve 使用基于FFT的重叠相加法计算 signal 与 kernel 的线性卷积，
+// 只返回与 signal 等长的前半部分
+func convolve(signal []float32, kernel []float32) []float32 {
+	output := make([]float32, len(signal))
+	if len(signal) == 0 || len(kernel) == 0 {
+		return output
+	}
+
+	fftSize := nextPowerOfTwo(2 * len(kernel))
+	if fftSize < 1024 {
+		fftSize = 1024
+	}
+	blockLen := fftSize - len(kernel) + 1
+
+	kernelSpectrum := make([]complex128, fftSize)
+	for i, value := range kernel {
+		kernelSpectrum[i] = complex(float64(value), 0)
+	}
+	fft(kernelSpectrum, false)
+
+	block := make([]complex128, fftSize)
+	for start := 0; start < len(signal); start += blockLen {
+		for i := range block {
+			block[i] = 0
+		}
+		for i := 0; i < blockLen && start+i < len(signal); i += 1 {
+			block[i] = complex(float64(signal[start+i]), 0)
+		}
+
+		fft(block, false)
+		for i := range block {
+			block[i] *= kernelSpectrum[i]
+		}
+		fft(block, true)
+
+		for i := 0; i < fftSize && start+i < len(output); i += 1 {
+			output[start+i] += float32(real(block[i]))
+		}
+	}
+
+	return output
+}
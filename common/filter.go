@@ -0,0 +1,90 @@
+package common
+
+import "math"
+
+// butterworthQ 由两级二阶节级联构成四阶巴特沃斯滤波器时各级的品质因数
+var butterworthQ = [2]float64{0.54119610, 1.30656296}
+
+// Biquad 二阶IIR滤波器(双二阶节)，系数按RBJ Audio EQ Cookbook计算
+type Biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+}
+
+// NewLowPassBiquad 构造一个二阶低通滤波器，cutoff 为截止频率(Hz)，q 为品质因数
+func NewLowPassBiquad(frameRate int, cutoff float64, q float64) Biquad {
+	w0 := 2 * math.Pi * cutoff / float64(frameRate)
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	a0 := 1 + alpha
+
+	return Biquad{
+		b0: (1 - cosW0) / 2 / a0,
+		b1: (1 - cosW0) / a0,
+		b2: (1 - cosW0) / 2 / a0,
+		a1: -2 * cosW0 / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// NewHighPassBiquad 构造一个二阶高通滤波器，cutoff 为截止频率(Hz)，q 为品质因数
+func NewHighPassBiquad(frameRate int, cutoff float64, q float64) Biquad {
+	w0 := 2 * math.Pi * cutoff / float64(frameRate)
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	a0 := 1 + alpha
+
+	return Biquad{
+		b0: (1 + cosW0) / 2 / a0,
+		b1: -(1 + cosW0) / a0,
+		b2: (1 + cosW0) / 2 / a0,
+		a1: -2 * cosW0 / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// Process 以零初始状态对采样序列滤波，返回新的采样序列
+func (b Biquad) Process(samples []float32) []float32 {
+	output := make([]float32, len(samples))
+	var x1, x2, y1, y2 float64
+	for n, value := range samples {
+		x0 := float64(value)
+		y0 := b.b0*x0 + b.b1*x1 + b.b2*x2 - b.a1*y1 - b.a2*y2
+		x2, x1 = x1, x0
+		y2, y1 = y1, y0
+		output[n] = float32(y0)
+	}
+
+	return output
+}
+
+// LowPass 使用四阶巴特沃斯低通滤波器滤波，返回新的FloatBuffer对象
+func (f *FloatBuffer) LowPass(cutoff float64) *FloatBuffer {
+	return f.applyBiquads(
+		NewLowPassBiquad(f.FrameRate, cutoff, butterworthQ[0]),
+		NewLowPassBiquad(f.FrameRate, cutoff, butterworthQ[1]))
+}
+
+// HighPass 使用四阶巴特沃斯高通滤波器滤波，返回新的FloatBuffer对象
+func (f *FloatBuffer) HighPass(cutoff float64) *FloatBuffer {
+	return f.applyBiquads(
+		NewHighPassBiquad(f.FrameRate, cutoff, butterworthQ[0]),
+		NewHighPassBiquad(f.FrameRate, cutoff, butterworthQ[1]))
+}
+
+// applyBiquads 依次使用各个滤波器对每个声道滤波
+func (f *FloatBuffer) applyBiquads(filters ...Biquad) *FloatBuffer {
+	buffer := &FloatBuffer{
+		Samples:   make([][]float32, f.Channels()),
+		FrameRate: f.FrameRate,
+	}
+
+	for i, samples := range f.Samples {
+		for _, filter := range filters {
+			samples = filter.Process(samples)
+		}
+		buffer.Samples[i] = samples
+	}
+
+	return buffer
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// int16Scale int16采样值与浮点采样值之间的换算系数
+const int16Scale = 32768.0
+
+// FloatBuffer float32格式的音频缓冲区对象
+//
+// 采样数据按声道拆分保存，数值范围为[-1, 1)，便于在浮点域中进行重采样、滤波和特征提取等处理，
+// 只在需要发送给ASRT服务端时再量化为16bit的Wav对象
+type FloatBuffer struct {
+	// Samples 按声道拆分的采样样本数据
+	Samples [][]float32
+	// FrameRate 采样频率，单位：Hz
+	FrameRate int
+}
+
+// NewFloatBuffer 获取一个新的指定长度的全零FloatBuffer对象
+func NewFloatBuffer(frameRate int, channels int, numFrames int) *FloatBuffer {
+	samples := make([][]float32, channels)
+	for i := 0; i < channels; i += 1 {
+		samples[i] = make([]float32, numFrames)
+	}
+
+	return &FloatBuffer{
+		Samples:   samples,
+		FrameRate: frameRate,
+	}
+}
+
+// Channels 获取声道数
+func (f *FloatBuffer) Channels() int {
+	return len(f.Samples)
+}
+
+// NumFrames 获取每个声道的采样点数
+func (f *FloatBuffer) NumFrames() int {
+	if len(f.Samples) == 0 {
+		return 0
+	}
+
+	return len(f.Samples[0])
+}
+
+// Duration 获取音频时长
+func (f *FloatBuffer) Duration() time.Duration {
+	if f.FrameRate <= 0 {
+		return 0
+	}
+
+	return FramesToDuration(f.NumFrames(), f.FrameRate)
+}
+
+// Clone 复制一份FloatBuffer对象
+func (f *FloatBuffer) Clone() *FloatBuffer {
+	buffer := NewFloatBuffer(f.FrameRate, f.Channels(), f.NumFrames())
+	for i := range f.Samples {
+		copy(buffer.Samples[i], f.Samples[i])
+	}
+
+	return buffer
+}
+
+// Slice 截取 [startFrame, endFrame) 范围内的采样数据，返回的对象与原对象共享存储
+func (f *FloatBuffer) Slice(startFrame int, endFrame int) *FloatBuffer {
+	if startFrame < 0 {
+		startFrame = 0
+	}
+	if endFrame > f.NumFrames() {
+		endFrame = f.NumFrames()
+	}
+	if endFrame < startFrame {
+		endFrame = startFrame
+	}
+
+	samples := make([][]float32, f.Channels())
+	for i := range f.Samples {
+		samples[i] = f.Samples[i][startFrame:endFrame]
+	}
+
+	return &FloatBuffer{
+		Samples:   samples,
+		FrameRate: f.FrameRate,
+	}
+}
+
+// MixToMono 将各声道取平均混合为单声道
+func (f *FloatBuffer) MixToMono() *FloatBuffer {
+	channels := f.Channels()
+	if channels == 1 {
+		return f.Clone()
+	}
+
+	buffer := NewFloatBuffer(f.FrameRate, 1, f.NumFrames())
+	for j := range buffer.Samples[0] {
+		var sum float32
+		for i := 0; i < channels; i += 1 {
+			sum += f.Samples[i][j]
+		}
+		buffer.Samples[0][j] = sum / float32(channels)
+	}
+
+	return buffer
+}
+
+// ToFloatBuffer 将Wav对象转换为FloatBuffer对象，16bit采样值的转换是无损的
+func (w *Wav) ToFloatBuffer() (*FloatBuffer, error) {
+	if w.SampleWidth != 2 {
+		return nil, fmt.Errorf("error: unsupport wave sample width `%d`", w.SampleWidth)
+	}
+
+	numFrames := w.NumFrames()
+	buffer := NewFloatBuffer(w.FrameRate, w.Channels, numFrames)
+	for j := 0; j < numFrames; j += 1 {
+		for i := 0; i < w.Channels; i += 1 {
+			buffer.Samples[i][j] = float32(w.Sample(i, j)) / int16Scale
+		}
+	}
+
+	return buffer, nil
+}
+
+// ToWav 将FloatBuffer对象四舍五入量化为16bit的Wav对象，超出范围的采样值会被截断
+//
+// 由 Wav.ToFloatBuffer 得到且未经修改的数据可以被无损地还原
+func (f *FloatBuffer) ToWav() Wav {
+	return f.quantize(nil)
+}
+
+// ToWavWithDither 使用TPDF(三角概率密度)抖动将FloatBuffer对象量化为16bit的Wav对象，
+// seed 为抖动噪声的随机数种子，相同的种子可以得到相同的结果
+func (f *FloatBuffer) ToWavWithDither(seed int64) Wav {
+	return f.quantize(rand.New(rand.NewSource(seed)))
+}
+
+// quantize 量化为16bit的Wav对象，rng不为nil时添加±1LSB的TPDF抖动
+func (f *FloatBuffer) quantize(rng *rand.Rand) Wav {
+	channels := f.Channels()
+	numFrames := f.NumFrames()
+	wave := NewBlankWav(f.FrameRate, channels, 2)
+	wave.data = make([]byte, numFrames*wave.blockAlign())
+
+	for j := 0; j < numFrames; j += 1 {
+		for i := 0; i < channels; i += 1 {
+			value := float64(f.Samples[i][j]) * int16Scale
+			if rng != nil {
+				value += rng.Float64() - rng.Float64()
+			}
+
+			wave.SetSample(i, j, clampInt16(math.Round(value)))
+		}
+	}
+
+	return wave
+}
+
+// clampInt16 将浮点数截断到int16的取值范围内
+func clampInt16(value float64) int16 {
+	if value > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if value < math.MinInt16 {
+		return math.MinInt16
+	}
+
+	return int16(value)
+}
+
+// durationToFrames 将时间换算为采样点数
+func durationToFrames(duration time.Duration, frameRate int) int {
+	if duration <= 0 {
+		return 0
+	}
+
+	return int(int64(duration) * int64(frameRate) / int64(time.Second))
+}
+
+// FramesToDuration 将采样率为 frameRate 的音频的采样点数换算为时间
+func FramesToDuration(frames int, frameRate int) time.Duration {
+	return time.Duration(frames) * time.Second / time.Duration(frameRate)
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitFloatBuffer(t *testing.T) {
+	suite.Run(t, new(TestUnitFloatBufferSuite))
+}
+
+type TestUnitFloatBufferSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitFloatBufferSuite) TestRoundTrip() {
+	wave := fixtureWav()
+	buffer, err := wave.ToFloatBuffer()
+	t.Equal(true, err == nil)
+	t.Equal(wave.Channels, buffer.Channels())
+	t.Equal(wave.NumFrames(), buffer.NumFrames())
+
+	wave2 := buffer.ToWav()
+	t.Equal(wave.GetRawSamples(), wave2.GetRawSamples())
+	t.Equal(wave.FrameRate, wave2.FrameRate)
+	t.Equal(wave.BytesPerSec, wave2.BytesPerSec)
+}
+
+func (t *TestUnitFloatBufferSuite) TestQuantize() {
+	buffer := NewFloatBuffer(16000, 1, 4)
+	buffer.Samples[0][0] = 2
+	buffer.Samples[0][1] = -2
+	buffer.Samples[0][2] = 0.5
+	buffer.Samples[0][3] = 0
+
+	wave := buffer.ToWav()
+	t.Equal([]int16{32767, -32768, 16384, 0}, wave.GetSamples()[0])
+
+	dithered1 := buffer.ToWavWithDither(1)
+	dithered2 := buffer.ToWavWithDither(1)
+	t.Equal(dithered1.GetRawSamples(), dithered2.GetRawSamples())
+	for i, value := range dithered1.GetSamples()[0] {
+		diff := int(value) - int(wave.GetSamples()[0][i])
+		t.True(diff >= -1 && diff <= 1)
+	}
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// dtmfRowFrequencies DTMF按键行频率，单位：Hz
+var dtmfRowFrequencies = [4]float64{697, 770, 852, 941}
+
+// dtmfColumnFrequencies DTMF按键列频率，单位：Hz
+var dtmfColumnFrequencies = [4]float64{1209, 1336, 1477, 1633}
+
+// dtmfKeypad DTMF键盘布局，行列分别对应行频率和列频率
+var dtmfKeypad = [4][4]rune{
+	{'1', '2', '3', 'A'},
+	{'4', '5', '6', 'B'},
+	{'7', '8', '9', 'C'},
+	{'*', '0', '#', 'D'},
+}
+
+// GenerateSine 生成正弦波音频，amplitude 为相对满幅的峰值幅度，取值范围[0, 1]
+func GenerateSine(frequency float64, amplitude float64, duration time.Duration, frameRate int, channels int) Wav {
+	buffer := newGeneratorBuffer(durationToFrames(duration, frameRate), frameRate, channels)
+	for n := range buffer.Samples[0] {
+		buffer.Samples[0][n] = float32(amplitude * math.Sin(2*math.Pi*frequency*float64(n)/float64(frameRate)))
+	}
+
+	return buffer.fillChannels().ToWav()
+}
+
+// GenerateSweep 生成频率从 startFrequency 线性变化到 endFrequency 的扫频信号
+func GenerateSweep(startFrequency float64, endFrequency float64, amplitude float64,
+	duration time.Duration, frameRate int, channels int,
+) Wav {
+	buffer := newGeneratorBuffer(durationToFrames(duration, frameRate), frameRate, channels)
+	seconds := duration.Seconds()
+	for n := range buffer.Samples[0] {
+		t := float64(n) / float64(frameRate)
+		// 瞬时频率 f(t) = f0 + (f1-f0)*t/T 的积分即为相位
+		phase := 2 * math.Pi * (startFrequency*t + (endFrequency-startFrequency)*t*t/(2*seconds))
+		buffer.Samples[0][n] = float32(amplitude * math.Sin(phase))
+	}
+
+	return buffer.fillChannels().ToWav()
+}
+
+// GenerateWhiteNoise 生成高斯白噪声，amplitude 为相对满幅的均方根幅度，各声道的噪声相互独立，
+// seed 为随机数种子，相同的种子得到相同的结果
+func GenerateWhiteNoise(amplitude float64, duration time.Duration, frameRate int, channels int, seed int64) Wav {
+	return generateNoise(whiteNoise, amplitude, duration, frameRate, channels, seed)
+}
+
+// GeneratePinkNoise 生成粉红噪声，参数含义同 GenerateWhiteNoise
+func GeneratePinkNoise(amplitude float64, duration time.Duration, frameRate int, channels int, seed int64) Wav {
+	return generateNoise(pinkNoise, amplitude, duration, frameRate, channels, seed)
+}
+
+// GenerateSilence 生成静音
+func GenerateSilence(duration time.Duration, frameRate int, channels int) Wav {
+	return newGeneratorBuffer(durationToFrames(duration, frameRate), frameRate, channels).ToWav()
+}
+
+// GenerateDTMF 生成按键音序列，每个按键持续 toneDuration，按键之间间隔 gapDuration 的静音，
+// digits 可包含 0-9、*、#、A-D
+func GenerateDTMF(digits string, toneDuration time.Duration, gapDuration time.Duration,
+	amplitude float64, frameRate int, channels int,
+) (Wav, error) {
+	toneFrames := durationToFrames(toneDuration, frameRate)
+	gapFrames := durationToFrames(gapDuration, frameRate)
+	buffer := newGeneratorBuffer(len(digits)*(toneFrames+gapFrames), frameRate, channels)
+
+	for index, digit := range strings.ToUpper(digits) {
+		row, column, ok := dtmfPosition(digit)
+		if !ok {
+			return Wav{}, fmt.Errorf("error: invalid dtmf digit `%c`", digit)
+		}
+
+		start := index * (toneFrames + gapFrames)
+		for n := 0; n < toneFrames; n += 1 {
+			t := float64(n) / float64(frameRate)
+			value := amplitude / 2 * (math.Sin(2*math.Pi*dtmfRowFrequencies[row]*t) +
+				math.Sin(2*math.Pi*dtmfColumnFrequencies[column]*t))
+			buffer.Samples[0][start+n] = float32(value)
+		}
+	}
+
+	return buffer.fillChannels().ToWav(), nil
+}
+
+// dtmfPosition 查找按键在DTMF键盘中的行列位置
+func dtmfPosition(digit rune) (row int, column int, ok bool) {
+	for row = range dtmfKeypad {
+		for column = range dtmfKeypad[row] {
+			if dtmfKeypad[row][column] == digit {
+				return row, column, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// generateNoise 使用给定的噪声生成函数生成各声道相互独立的噪声
+func generateNoise(generate func(int, *rand.Rand) []float32, amplitude float64,
+	duration time.Duration, frameRate int, channels int, seed int64,
+) Wav {
+	buffer := newGeneratorBuffer(durationToFrames(duration, frameRate), frameRate, channels)
+	rng := rand.New(rand.NewSource(seed))
+	for i := range buffer.Samples {
+		noise := generate(buffer.NumFrames(), rng)
+		gain := 0.0
+		if power := meanSquare(noise); power > 0 {
+			gain = amplitude / math.Sqrt(power)
+		}
+		for n, value := range noise {
+			buffer.Samples[i][n] = float32(gain) * value
+		}
+	}
+
+	return buffer.ToWav()
+}
+
+// newGeneratorBuffer 构造生成器所用的全零FloatBuffer对象，声道数至少为1
+func newGeneratorBuffer(numFrames int, frameRate int, channels int) *FloatBuffer {
+	if channels < 1 {
+		channels = 1
+	}
+
+	return NewFloatBuffer(frameRate, channels, numFrames)
+}
+
+// fillChannels 将第一个声道的数据复制到其余各声道
+func (f *FloatBuffer) fillChannels() *FloatBuffer {
+	for i := 1; i < len(f.Samples); i += 1 {
+		copy(f.Samples[i], f.Samples[0])
+	}
+
+	return f
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitGenerator(t *testing.T) {
+	suite.Run(t, new(TestUnitGeneratorSuite))
+}
+
+type TestUnitGeneratorSuite struct {
+	suite.Suite
+}
+
+// fixtureWav 代替录音文件的测试用音频：静音、扫频、粉红噪声和静音依次拼接，16kHz单声道，时长2.6秒
+func fixtureWav() Wav {
+	wave := GenerateSilence(300*time.Millisecond, 16000, 1)
+	_ = wave.AppendWav(GenerateSweep(200, 4000, 0.5, time.Second, 16000, 1))
+	_ = wave.AppendWav(GeneratePinkNoise(0.1, time.Second, 16000, 1, 1))
+	_ = wave.AppendWav(GenerateSilence(300*time.Millisecond, 16000, 1))
+
+	return wave
+}
+
+// fixtureWavBytes 序列化后的 fixtureWav
+func fixtureWavBytes() []byte {
+	wave := fixtureWav()
+	wavBytes, _ := wave.Serialize()
+	return wavBytes
+}
+
+func (t *TestUnitGeneratorSuite) TestGenerateSine() {
+	wave := GenerateSine(1000, 0.5, 500*time.Millisecond, 8000, 2)
+	t.Equal(8000, wave.FrameRate)
+	t.Equal(2, wave.Channels)
+	t.Equal(2, wave.SampleWidth)
+	t.Equal(4000, wave.NumFrames())
+
+	samples := wave.GetSamples()
+	t.Equal(samples[0], samples[1])
+	// 8kHz采样的1kHz正弦波每8个点一个周期
+	t.Equal(int16(0), samples[0][0])
+	t.Equal(int16(16384), samples[0][2])
+	t.Equal(int16(-16384), samples[0][6])
+
+	// 生成的音频可以正常序列化和反序列化
+	wavBytes, err := wave.Serialize()
+	t.Nil(err)
+	wave2 := Wav{}
+	err = wave2.Deserialize(wavBytes)
+	t.Nil(err)
+	t.Equal(wave.GetRawSamples(), wave2.GetRawSamples())
+}
+
+func (t *TestUnitGeneratorSuite) TestGenerateSweep() {
+	wave := GenerateSweep(100, 4000, 0.5, time.Second, 16000, 1)
+	t.Equal(16000, wave.NumFrames())
+
+	buffer, err := wave.ToFloatBuffer()
+	t.Nil(err)
+	start := zeroCrossings(buffer.Samples[0][:1600])
+	end := zeroCrossings(buffer.Samples[0][14400:])
+	t.Less(start, end)
+}
+
+func (t *TestUnitGeneratorSuite) TestGenerateNoise() {
+	for _, generate := range []func(float64, time.Duration, int, int, int64) Wav{
+		GenerateWhiteNoise, GeneratePinkNoise,
+	} {
+		wave1 := generate(0.1, time.Second, 16000, 2, 7)
+		wave2 := generate(0.1, time.Second, 16000, 2, 7)
+		wave3 := generate(0.1, time.Second, 16000, 2, 8)
+		t.Equal(wave1.GetRawSamples(), wave2.GetRawSamples())
+		t.NotEqual(wave1.GetRawSamples(), wave3.GetRawSamples())
+
+		buffer, err := wave1.ToFloatBuffer()
+		t.Nil(err)
+		t.NotEqual(buffer.Samples[0], buffer.Samples[1])
+		t.InDelta(0.1, math.Sqrt(meanSquare(buffer.Samples[0])), 0.001)
+	}
+}
+
+func (t *TestUnitGeneratorSuite) TestGenerateSilence() {
+	wave := GenerateSilence(250*time.Millisecond, 16000, 1)
+	t.Equal(4000, wave.NumFrames())
+	t.Equal(make([]byte, 8000), wave.GetRawSamples())
+}
+
+func (t *TestUnitGeneratorSuite) TestGenerateDTMF() {
+	wave, err := GenerateDTMF("1#", 100*time.Millisecond, 50*time.Millisecond, 0.8, 8000, 1)
+	t.Nil(err)
+	t.Equal(2*1200, wave.NumFrames())
+	samples := wave.GetSamples()[0]
+	t.Equal(int16(0), samples[1000])
+	t.NotEqual(int16(0), samples[1201])
+
+	_, err = GenerateDTMF("1x", 100*time.Millisecond, 50*time.Millisecond, 0.8, 8000, 1)
+	t.NotNil(err)
+}
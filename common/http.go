@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"runtime"
@@ -15,11 +14,15 @@ import (
 var httpUserAgent string = fmt.Sprintf("%s%s%s%s%s", "ASRT-SDK client/", "v1",
 	" (", runtime.Version(), ") (https://asrt.ailemon.net/)")
 
-// SendHTTPRequestGet 发送HTTP GET请求
+// defaultHTTPClient 默认的HTTP客户端，在所有请求之间共享以复用连接
+var defaultHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// SendHTTPRequestGet 发送HTTP GET请求，非200的HTTP状态不视为错误，需要检查状态码时请使用 DoHTTPRequest
 func SendHTTPRequestGet(url string) ([]byte, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		log.Fatalln("error:", err)
 		return nil, err
 	}
 
@@ -27,25 +30,17 @@ func SendHTTPRequestGet(url string) ([]byte, error) {
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalln("error:", err)
 		return nil, err
 	}
 
-	if resp.StatusCode == 200 {
-		log.Println("info: http status 200 ok")
-	} else {
-		log.Println("warning: http status ", resp.StatusCode)
-	}
-
 	return body, nil
 }
 
-// SendHTTPRequestPost 发送HTTP POST请求
+// SendHTTPRequestPost 发送HTTP POST请求，非200的HTTP状态不视为错误，需要检查状态码时请使用 DoHTTPRequest
 func SendHTTPRequestPost(url string, bytesForm []byte, contentType string) ([]byte, error) {
 	bodyReader := bytes.NewReader(bytesForm)
 	resp, err := http.Post(url, contentType, bodyReader)
 	if err != nil {
-		log.Fatalln("error:", err)
 		return nil, err
 	}
 
@@ -53,27 +48,16 @@ func SendHTTPRequestPost(url string, bytesForm []byte, contentType string) ([]by
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalln("error:", err)
 		return nil, err
 	}
 
-	if resp.StatusCode == 200 {
-		log.Println("info: http status 200 ok")
-	} else {
-		log.Println("warning: http status ", resp.StatusCode)
-	}
-
 	return body, nil
 }
 
-// SendHTTPRequest 发送HTTP请求
+// SendHTTPRequest 发送HTTP请求，非200的HTTP状态不视为错误，需要检查状态码时请使用 DoHTTPRequest
 func SendHTTPRequest(url string, method string,
 	bytesBody []byte, contentType string,
 ) ([]byte, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
 	var bodyReader io.Reader = nil
 	if method == "POST" {
 		bodyReader = bytes.NewReader(bytesBody)
@@ -89,7 +73,7 @@ func SendHTTPRequest(url string, method string,
 		req.Header.Set("Content-Type", contentType)
 	}
 
-	rsp, err := client.Do(req)
+	rsp, err := defaultHTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -100,11 +84,46 @@ func SendHTTPRequest(url string, method string,
 		return nil, err
 	}
 
-	if rsp.StatusCode != 200 {
-		log.Println("warning: http status ", rsp.StatusCode)
+	return rspBody, nil
+}
+
+// HTTPResponse HTTP响应
+type HTTPResponse struct {
+	// StatusCode HTTP响应状态码
+	StatusCode int
+	// Header HTTP响应头
+	Header http.Header
+	// Body HTTP响应体
+	Body []byte
+}
+
+// DoHTTPRequest 使用给定的客户端发送HTTP请求并读取完整的响应，client 为nil时使用默认配置的客户端。
+// 只有网络传输出错时才返回错误，非200的HTTP状态需要调用方根据 StatusCode 自行处理
+func DoHTTPRequest(client *http.Client, req *http.Request) (*HTTPResponse, error) {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", httpUserAgent)
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+	rspBody, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	return rspBody, nil
+	return &HTTPResponse{
+		StatusCode: rsp.StatusCode,
+		Header:     rsp.Header,
+		Body:       rspBody,
+	}, nil
 }
 
 // URLEncode URL编码
@@ -115,12 +134,11 @@ func URLEncode(text string) string {
 }
 
 // URLDecode URL解码
-func URLDecode(text string) string {
+func URLDecode(text string) (string, error) {
 	enEscapeURL, err := url.QueryUnescape(text)
 	if err != nil {
-		log.Fatalln("error: URL decode failed.", err)
-		return ""
+		return "", fmt.Errorf("error: URL decode failed, %s", err.Error())
 	}
 
-	return enEscapeURL
+	return enEscapeURL, nil
 }
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// LogLevel 日志级别
+type LogLevel int
+
+const (
+	// LevelDebug 调试级别
+	LevelDebug LogLevel = iota
+	// LevelInfo 信息级别
+	LevelInfo
+	// LevelWarn 警告级别
+	LevelWarn
+	// LevelError 错误级别
+	LevelError
+)
+
+// String 获取日志级别名称
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warning"
+	case LevelError:
+		return "error"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Field 结构化日志字段
+type Field struct {
+	// Key 字段名
+	Key string
+	// Value 字段值
+	Value interface{}
+}
+
+// F 构造一个结构化日志字段
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger 分级的结构化日志接口
+//
+// SDK默认不输出任何日志，可以通过 sdk.WithLogger 注入实现。
+// 对接常用日志库时，通常只需要用 LoggerFunc 包装一个转换函数即可
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// NopLogger 不输出任何内容的日志实现
+type NopLogger struct{}
+
+// Debug 实现 Logger 接口
+func (NopLogger) Debug(msg string, fields ...Field) {}
+
+// Info 实现 Logger 接口
+func (NopLogger) Info(msg string, fields ...Field) {}
+
+// Warn 实现 Logger 接口
+func (NopLogger) Warn(msg string, fields ...Field) {}
+
+// Error 实现 Logger 接口
+func (NopLogger) Error(msg string, fields ...Field) {}
+
+// LoggerFunc 将一个日志函数适配为 Logger 接口
+type LoggerFunc func(level LogLevel, msg string, fields []Field)
+
+// Debug 实现 Logger 接口
+func (f LoggerFunc) Debug(msg string, fields ...Field) {
+	f(LevelDebug, msg, fields)
+}
+
+// Info 实现 Logger 接口
+func (f LoggerFunc) Info(msg string, fields ...Field) {
+	f(LevelInfo, msg, fields)
+}
+
+// Warn 实现 Logger 接口
+func (f LoggerFunc) Warn(msg string, fields ...Field) {
+	f(LevelWarn, msg, fields)
+}
+
+// Error 实现 Logger 接口
+func (f LoggerFunc) Error(msg string, fields ...Field) {
+	f(LevelError, msg, fields)
+}
+
+// NewStdLogger 构造一个基于标准库 log.Logger 的日志实现，只输出不低于 minLevel 级别的日志，
+// 输出格式为 "level: msg key=value ..."，logger 为nil时使用标准库的默认Logger
+func NewStdLogger(logger *log.Logger, minLevel LogLevel) Logger {
+	return LoggerFunc(func(level LogLevel, msg string, fields []Field) {
+		if level < minLevel {
+			return
+		}
+
+		var builder strings.Builder
+		builder.WriteString(level.String())
+		builder.WriteString(": ")
+		builder.WriteString(msg)
+		for _, field := range fields {
+			fmt.Fprintf(&builder, " %s=%v", field.Key, field.Value)
+		}
+
+		if logger == nil {
+			log.Println(builder.String())
+		} else {
+			logger.Println(builder.String())
+		}
+	})
+}
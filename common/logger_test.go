@@ -0,0 +1,69 @@
+package common
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitLogger(t *testing.T) {
+	suite.Run(t, new(TestUnitLoggerSuite))
+}
+
+type TestUnitLoggerSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitLoggerSuite) TestStdLogger() {
+	var out bytes.Buffer
+	logger := NewStdLogger(log.New(&out, "", 0), LevelWarn)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	t.Equal("", out.String())
+
+	logger.Warn("unexpected http status", F("op", "all"), F("http_status", 503))
+	logger.Error("failed")
+	t.Equal("warning: unexpected http status op=all http_status=503\nerror: failed\n", out.String())
+}
+
+func (t *TestUnitLoggerSuite) TestStdLoggerDefault() {
+	var out bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&out)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}()
+
+	logger := NewStdLogger(nil, LevelDebug)
+	logger.Debug("hello", F("n", 1))
+	t.Equal("debug: hello n=1\n", out.String())
+}
+
+func (t *TestUnitLoggerSuite) TestLoggerFunc() {
+	var levels []LogLevel
+	var messages []string
+	var fields [][]Field
+	logger := LoggerFunc(func(level LogLevel, msg string, f []Field) {
+		levels = append(levels, level)
+		messages = append(messages, msg)
+		fields = append(fields, f)
+	})
+
+	logger.Debug("a")
+	logger.Info("b", F("k", "v"))
+	logger.Warn("c")
+	logger.Error("d", F("x", 1), F("y", 2))
+	t.Equal([]LogLevel{LevelDebug, LevelInfo, LevelWarn, LevelError}, levels)
+	t.Equal([]string{"a", "b", "c", "d"}, messages)
+	t.Equal([]Field{{Key: "k", Value: "v"}}, fields[1])
+	t.Equal([]Field{F("x", 1), F("y", 2)}, fields[3])
+
+	t.Equal("debug", LevelDebug.String())
+	t.Equal("warning", LevelWarn.String())
+	t.Equal("level(9)", LogLevel(9).String())
+}
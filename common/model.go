@@ -1,5 +1,7 @@
 package common
 
+import "time"
+
 var (
 	APIStatusCodeOK                 int = 200000 // OK
 	APIStatusCodePartOK             int = 206000 // 部分识别结果
@@ -15,6 +17,26 @@ type AsrtAPIResponse struct {
 	StatusCode    int         `json:"status_code"`
 	StatucMesaage string      `json:"status_message"`
 	Result        interface{} `json:"result"`
+	// Segment 长音频识别时该结果对应的音频片段信息，其他情况下为nil
+	Segment *AsrtSegmentInfo `json:"-"`
+	// RequestID 本次请求的ID，服务端在响应中回传了请求ID时为服务端返回的值
+	RequestID string `json:"-"`
+	// ServerTiming 服务端通过 Server-Timing 响应头或gRPC元数据返回的耗时信息
+	ServerTiming []ServerTiming `json:"-"`
+}
+
+// AsrtSegmentInfo 长音频识别结果对应的音频片段信息
+type AsrtSegmentInfo struct {
+	// Start 片段在原始音频时间轴上的起始时间
+	Start time.Duration
+	// End 片段在原始音频时间轴上的结束时间
+	End time.Duration
+	// SpeechRate 片段的估计语速，单位：音节/秒，未进行语速估计时为0
+	SpeechRate float64
+	// StretchRate 识别前对片段所做时间伸缩的语速倍率，1表示未做伸缩
+	StretchRate float64
+	// DTMF 片段内检测到的DTMF按键音，时间为原始音频时间轴上的时间，未开启检测时为nil
+	DTMF []DTMFTone
 }
 
 // AsrtAPISpeechRequest ASRT语音识别API语音数据请求类
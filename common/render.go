@@ -0,0 +1,331 @@
+package common
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// defaultSpectrogramWindow 频谱图默认的分析窗长
+	defaultSpectrogramWindow = 25 * time.Millisecond
+	// defaultSpectrogramHop 频谱图默认的帧移
+	defaultSpectrogramHop = 10 * time.Millisecond
+	// defaultSpectrogramRange 频谱图默认显示的动态范围，单位：dB
+	defaultSpectrogramRange = 80
+	// defaultWaveformWidth 波形图默认宽度，单位：像素
+	defaultWaveformWidth = 1200
+	// defaultWaveformHeight 波形图默认高度，单位：像素
+	defaultWaveformHeight = 200
+)
+
+// spectrogramPalette 频谱图配色，从低能量到高能量线性插值
+var spectrogramPalette = []color.RGBA{
+	{0, 0, 4, 255},
+	{87, 16, 110, 255},
+	{188, 55, 84, 255},
+	{249, 142, 9, 255},
+	{252, 255, 164, 255},
+}
+
+// regionColors 标注区间使用的颜色，相邻区间交替使用
+var regionColors = []color.RGBA{
+	{0, 200, 255, 255},
+	{120, 255, 120, 255},
+}
+
+// Region 需要在图像上标注的时间区间，例如语音活动检测结果或长音频识别的片段边界，
+// 结束时间早于起始时间的区间不会被绘制
+type Region struct {
+	// Start 区间起始时间
+	Start time.Duration
+	// End 区间结束时间
+	End time.Duration
+	// Label 区间标签，只在SVG波形图中显示
+	Label string
+}
+
+// RegionsFromResponses 将长音频识别结果的片段信息转换为标注区间，标签为识别文本
+func RegionsFromResponses(responses []*AsrtAPIResponse) []Region {
+	regions := make([]Region, 0, len(responses))
+	for _, response := range responses {
+		if response == nil || response.Segment == nil {
+			continue
+		}
+
+		label := ""
+		if text, ok := response.Result.(string); ok {
+			label = text
+		}
+		regions = append(regions, Region{
+			Start: response.Segment.Start,
+			End:   response.Segment.End,
+			Label: label,
+		})
+	}
+
+	return regions
+}
+
+// SpectrogramOptions 频谱图绘制参数，零值表示使用默认值
+type SpectrogramOptions struct {
+	// Width 图像宽度，单位：像素，为0时每个分析帧占一列
+	Width int
+	// Height 图像高度，单位：像素，为0时每个频点占一行
+	Height int
+	// Window 分析窗长，默认25ms
+	Window time.Duration
+	// Hop 帧移，默认10ms
+	Hop time.Duration
+	// DynamicRange 显示的动态范围，单位：dB，默认80dB
+	DynamicRange float64
+	// Regions 需要标注的时间区间，以区间边界的竖线和顶部色条表示
+	Regions []Region
+}
+
+// RenderSpectrogram 将音频的语谱图绘制为PNG图像并写入 writer，多声道音频会先混合为单声道
+func (f *FloatBuffer) RenderSpectrogram(writer io.Writer, options SpectrogramOptions) error {
+	if f.FrameRate <= 0 {
+		return fmt.Errorf("error: invalid frame rate `%d`", f.FrameRate)
+	}
+	if options.Window <= 0 {
+		options.Window = defaultSpectrogramWindow
+	}
+	if options.Hop <= 0 {
+		options.Hop = defaultSpectrogramHop
+	}
+	if options.DynamicRange <= 0 {
+		options.DynamicRange = defaultSpectrogramRange
+	}
+
+	spectrum := f.powerSpectrogram(options.Window, options.Hop)
+	if len(spectrum) == 0 {
+		return fmt.Errorf("error: audio is too short to render a spectrogram")
+	}
+
+	numBins := len(spectrum[0])
+	width := options.Width
+	if width <= 0 {
+		width = len(spectrum)
+	}
+	height := options.Height
+	if height <= 0 {
+		height = numBins
+	}
+
+	maxPower := math.Inf(-1)
+	for _, frame := range spectrum {
+		for _, power := range frame {
+			maxPower = math.Max(maxPower, power)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x += 1 {
+		frame := spectrum[x*len(spectrum)/width]
+		for y := 0; y < height; y += 1 {
+			// 低频在图像底部
+			bin := (height - 1 - y) * numBins / height
+			level := 1 - (maxPower-frame[bin])/options.DynamicRange
+			img.SetRGBA(x, y, paletteColor(level))
+		}
+	}
+
+	duration := f.Duration()
+	for i, region := range options.Regions {
+		if region.End < region.Start {
+			continue
+		}
+		regionColor := regionColors[i%len(regionColors)]
+		startX := timeToPixel(region.Start, duration, width)
+		endX := timeToPixel(region.End, duration, width)
+		for x := startX; x <= endX && x < width; x += 1 {
+			for y := 0; y < 4 && y < height; y += 1 {
+				img.SetRGBA(x, y, regionColor)
+			}
+		}
+		for _, x := range []int{startX, endX} {
+			if x >= width {
+				x = width - 1
+			}
+			for y := 0; y < height; y += 1 {
+				img.SetRGBA(x, y, regionColor)
+			}
+		}
+	}
+
+	return png.Encode(writer, img)
+}
+
+// RenderSpectrogram 将Wav对象的语谱图绘制为PNG图像，参见 FloatBuffer.RenderSpectrogram
+func (w *Wav) RenderSpectrogram(writer io.Writer, options SpectrogramOptions) error {
+	buffer, err := w.ToFloatBuffer()
+	if err != nil {
+		return err
+	}
+
+	return buffer.RenderSpectrogram(writer, options)
+}
+
+// WaveformOptions 波形图绘制参数，零值表示使用默认值
+type WaveformOptions struct {
+	// Width 图像宽度，单位：像素，默认1200
+	Width int
+	// Height 每个声道的波形高度，单位：像素，默认200
+	Height int
+	// Regions 需要标注的时间区间，以半透明色块和标签文字表示
+	Regions []Region
+}
+
+// RenderWaveformSVG 将音频各声道的波形绘制为SVG图像并写入 writer
+func (f *FloatBuffer) RenderWaveformSVG(writer io.Writer, options WaveformOptions) error {
+	if options.Width <= 0 {
+		options.Width = defaultWaveformWidth
+	}
+	if options.Height <= 0 {
+		options.Height = defaultWaveformHeight
+	}
+
+	channels := f.Channels()
+	numFrames := f.NumFrames()
+	width := options.Width
+	totalHeight := options.Height * channels
+	duration := f.Duration()
+
+	out := bufio.NewWriter(writer)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, totalHeight, width, totalHeight)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, totalHeight)
+
+	for i, region := range options.Regions {
+		if region.End < region.Start {
+			continue
+		}
+		startX := timeToPixel(region.Start, duration, width)
+		endX := timeToPixel(region.End, duration, width)
+		regionColor := regionColors[i%len(regionColors)]
+		fill := fmt.Sprintf("#%02x%02x%02x", regionColor.R, regionColor.G, regionColor.B)
+		fmt.Fprintf(out, `<rect x="%d" y="0" width="%d" height="%d" fill="%s" fill-opacity="0.25"/>`+"\n",
+			startX, endX-startX, totalHeight, fill)
+		if region.Label != "" {
+			fmt.Fprintf(out, `<text x="%d" y="14" font-size="12" font-family="sans-serif">`, startX+2)
+			xml.EscapeText(out, []byte(region.Label))
+			fmt.Fprint(out, "</text>\n")
+		}
+	}
+
+	for i, samples := range f.Samples {
+		top := i * options.Height
+		center := float64(top) + float64(options.Height)/2
+		halfHeight := float64(options.Height) / 2
+		fmt.Fprintf(out, `<line x1="0" y1="%.1f" x2="%d" y2="%.1f" stroke="#cccccc"/>`+"\n", center, width, center)
+
+		fmt.Fprint(out, `<path fill="none" stroke="#1f77b4" stroke-width="1" d="`)
+		for x := 0; x < width && numFrames > 0; x += 1 {
+			first := x * numFrames / width
+			last := (x + 1) * numFrames / width
+			if last <= first {
+				last = first + 1
+			}
+
+			minValue, maxValue := float32(0), float32(0)
+			for _, value := range samples[first:last] {
+				if value < minValue {
+					minValue = value
+				}
+				if value > maxValue {
+					maxValue = value
+				}
+			}
+			fmt.Fprintf(out, "M%d %.1fV%.1f", x, center-float64(maxValue)*halfHeight, center-float64(minValue)*halfHeight)
+		}
+		fmt.Fprint(out, `"/>`+"\n")
+	}
+
+	fmt.Fprint(out, "</svg>\n")
+	return out.Flush()
+}
+
+// RenderWaveformSVG 将Wav对象的波形绘制为SVG图像，参见 FloatBuffer.RenderWaveformSVG
+func (w *Wav) RenderWaveformSVG(writer io.Writer, options WaveformOptions) error {
+	buffer, err := w.ToFloatBuffer()
+	if err != nil {
+		return err
+	}
+
+	return buffer.RenderWaveformSVG(writer, options)
+}
+
+// powerSpectrogram 计算分帧功率谱，单位：dB，每帧包含 fftSize/2+1 个频点
+func (f *FloatBuffer) powerSpectrogram(window time.Duration, hop time.Duration) [][]float64 {
+	samples := f.MixToMono().Samples[0]
+	windowLen := durationToFrames(window, f.FrameRate)
+	hopLen := durationToFrames(hop, f.FrameRate)
+	if windowLen <= 0 || hopLen <= 0 || len(samples) < windowLen {
+		return nil
+	}
+
+	fftSize := nextPowerOfTwo(windowLen)
+	hann := hannWindow(windowLen)
+	block := make([]complex128, fftSize)
+
+	var spectrum [][]float64
+	for start := 0; start+windowLen <= len(samples); start += hopLen {
+		for i := range block {
+			block[i] = 0
+		}
+		for i, value := range samples[start : start+windowLen] {
+			block[i] = complex(float64(value*hann[i]), 0)
+		}
+		fft(block, false)
+
+		frame := make([]float64, fftSize/2+1)
+		for k := range frame {
+			re, im := real(block[k]), imag(block[k])
+			frame[k] = 10 * math.Log10(re*re+im*im+1e-12)
+		}
+		spectrum = append(spectrum, frame)
+	}
+
+	return spectrum
+}
+
+// paletteColor 根据[0, 1]范围内的能量等级插值得到颜色
+func paletteColor(level float64) color.RGBA {
+	if level <= 0 {
+		return spectrogramPalette[0]
+	}
+	if level >= 1 {
+		return spectrogramPalette[len(spectrogramPalette)-1]
+	}
+
+	position := level * float64(len(spectrogramPalette)-1)
+	index := int(position)
+	fraction := position - float64(index)
+	from, to := spectrogramPalette[index], spectrogramPalette[index+1]
+	mix := func(a uint8, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*fraction)
+	}
+
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+}
+
+// timeToPixel 将时间换算为图像上的横坐标
+func timeToPixel(t time.Duration, duration time.Duration, width int) int {
+	if duration <= 0 || t <= 0 {
+		return 0
+	}
+
+	x := int(int64(t) * int64(width) / int64(duration))
+	if x > width {
+		x = width
+	}
+
+	return x
+}
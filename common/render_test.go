@@ -0,0 +1,99 @@
+package common
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitRender(t *testing.T) {
+	suite.Run(t, new(TestUnitRenderSuite))
+}
+
+type TestUnitRenderSuite struct {
+	suite.Suite
+	wave Wav
+}
+
+func (t *TestUnitRenderSuite) SetupTest() {
+	t.wave = fixtureWav()
+}
+
+func (t *TestUnitRenderSuite) TestRenderSpectrogram() {
+	buffer, err := t.wave.ToFloatBuffer()
+	t.Nil(err)
+	regions := buffer.DetectSpeechRegions()
+	t.NotEqual(0, len(regions))
+
+	var out bytes.Buffer
+	err = t.wave.RenderSpectrogram(&out, SpectrogramOptions{Regions: regions})
+	t.Nil(err)
+
+	img, err := png.Decode(&out)
+	t.Nil(err)
+	// 默认每帧一列，25ms窗长在16kHz下对应512点FFT
+	t.Equal((t.wave.NumFrames()-400)/160+1, img.Bounds().Dx())
+	t.Equal(257, img.Bounds().Dy())
+
+	out.Reset()
+	err = t.wave.RenderSpectrogram(&out, SpectrogramOptions{Width: 300, Height: 100})
+	t.Nil(err)
+	img, err = png.Decode(&out)
+	t.Nil(err)
+	t.Equal(300, img.Bounds().Dx())
+	t.Equal(100, img.Bounds().Dy())
+
+	short := GenerateSilence(time.Millisecond, 16000, 1)
+	err = short.RenderSpectrogram(&out, SpectrogramOptions{})
+	t.NotNil(err)
+}
+
+func (t *TestUnitRenderSuite) TestRenderWaveformSVG() {
+	responses := []*AsrtAPIResponse{
+		{Result: "你好<世界>", Segment: &AsrtSegmentInfo{Start: 0, End: time.Second}},
+		{Result: "再见", Segment: &AsrtSegmentInfo{Start: time.Second, End: 2 * time.Second}},
+		{Result: "没有片段信息"},
+	}
+	regions := RegionsFromResponses(responses)
+	t.Equal(2, len(regions))
+
+	stereo := GenerateSine(440, 0.5, 2*time.Second, 16000, 2)
+	var out bytes.Buffer
+	err := stereo.RenderWaveformSVG(&out, WaveformOptions{Width: 400, Height: 100, Regions: regions})
+	t.Nil(err)
+
+	svg := out.String()
+	t.True(strings.HasPrefix(svg, "<svg"))
+	t.Contains(svg, `height="200"`)
+	t.Contains(svg, "你好&lt;世界&gt;")
+	t.Equal(2, strings.Count(svg, "<path"))
+
+	// 输出应为合法的XML
+	decoder := xml.NewDecoder(&out)
+	for {
+		_, err = decoder.Token()
+		if err != nil {
+			break
+		}
+	}
+	t.Equal("EOF", err.Error())
+}
+
+func (t *TestUnitRenderSuite) TestInvalidRegion() {
+	regions := []Region{{Start: 2 * time.Second, End: time.Second, Label: "倒置"}}
+
+	var out bytes.Buffer
+	err := t.wave.RenderWaveformSVG(&out, WaveformOptions{Width: 400, Height: 100, Regions: regions})
+	t.Nil(err)
+	t.NotContains(out.String(), "倒置")
+	t.NotContains(out.String(), `width="-`)
+
+	out.Reset()
+	err = t.wave.RenderSpectrogram(&out, SpectrogramOptions{Regions: regions})
+	t.Nil(err)
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// resampleZeroCrossings 重采样插值核在每一侧包含的过零点个数
+const resampleZeroCrossings = 16
+
+// Resample 使用加窗sinc插值将音频重采样到指定的采样频率，返回新的FloatBuffer对象
+func (f *FloatBuffer) Resample(frameRate int) (*FloatBuffer, error) {
+	if frameRate <= 0 || f.FrameRate <= 0 {
+		return nil, fmt.Errorf("error: invalid frame rate `%d` -> `%d`", f.FrameRate, frameRate)
+	}
+	if frameRate == f.FrameRate {
+		return f.Clone(), nil
+	}
+
+	buffer := f.resampleRatio(float64(frameRate) / float64(f.FrameRate))
+	buffer.FrameRate = frameRate
+	return buffer, nil
+}
+
+// resampleRatio 按输出/输入采样点数之比 ratio 重采样，不修改 FrameRate
+func (f *FloatBuffer) resampleRatio(ratio float64) *FloatBuffer {
+	outFrames := int(math.Round(float64(f.NumFrames()) * ratio))
+	buffer := NewFloatBuffer(f.FrameRate, f.Channels(), outFrames)
+
+	// 降采样时需要同时降低截止频率以抗混叠
+	cutoff := 0.95 * math.Min(1, ratio)
+	halfWidth := float64(resampleZeroCrossings) / cutoff
+
+	for i, samples := range f.Samples {
+		output := buffer.Samples[i]
+		for j := range output {
+			t := float64(j) / ratio
+			first := int(math.Ceil(t - halfWidth))
+			last := int(math.Floor(t + halfWidth))
+			if first < 0 {
+				first = 0
+			}
+			if last > len(samples)-1 {
+				last = len(samples) - 1
+			}
+
+			var sum float64
+			for k := first; k <= last; k += 1 {
+				x := t - float64(k)
+				sum += float64(samples[k]) * cutoff * sinc(cutoff*x) * blackman(x/halfWidth)
+			}
+			output[j] = float32(sum)
+		}
+	}
+
+	return buffer
+}
+
+// sinc 归一化sinc函数
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman 以0为中心、定义域为[-1, 1]的布莱克曼窗
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerTiming 服务端返回的一项耗时信息，格式参见 W3C Server Timing 规范，
+// 例如 "infer;dur=123.4;desc=\"acoustic model\""
+type ServerTiming struct {
+	// Name 耗时项名称
+	Name string
+	// Duration 耗时，未返回时为0
+	Duration time.Duration
+	// Description 耗时项描述
+	Description string
+}
+
+// ParseServerTiming 解析一个或多个 Server-Timing 响应头的值，忽略格式不正确的耗时项
+func ParseServerTiming(values ...string) []ServerTiming {
+	var timings []ServerTiming
+	for _, value := range values {
+		for _, metric := range splitOutsideQuotes(value, ',') {
+			params := splitOutsideQuotes(metric, ';')
+			timing := ServerTiming{Name: strings.TrimSpace(params[0])}
+			if timing.Name == "" {
+				continue
+			}
+
+			for _, param := range params[1:] {
+				key, paramValue := param, ""
+				if i := strings.Index(param, "="); i >= 0 {
+					key, paramValue = param[:i], unquote(strings.TrimSpace(param[i+1:]))
+				}
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "dur":
+					milliseconds, err := strconv.ParseFloat(paramValue, 64)
+					if err == nil {
+						timing.Duration = time.Duration(milliseconds * float64(time.Millisecond))
+					}
+				case "desc":
+					timing.Description = paramValue
+				}
+			}
+			timings = append(timings, timing)
+		}
+	}
+
+	return timings
+}
+
+// splitOutsideQuotes 按 separator 拆分字符串，忽略双引号内的分隔符
+func splitOutsideQuotes(value string, separator byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(value); i += 1 {
+		switch {
+		case value[i] == '\\' && quoted:
+			i += 1
+		case value[i] == '"':
+			quoted = !quoted
+		case value[i] == separator && !quoted:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// unquote 去掉值两端的双引号并处理转义字符，不是带引号的字符串时原样返回
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+
+	var builder strings.Builder
+	for i := 1; i < len(value)-1; i += 1 {
+		if value[i] == '\\' && i+1 < len(value)-1 {
+			i += 1
+		}
+		builder.WriteByte(value[i])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitServerTiming(t *testing.T) {
+	suite.Run(t, new(TestUnitServerTimingSuite))
+}
+
+type TestUnitServerTimingSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitServerTimingSuite) TestParse() {
+	timings := ParseServerTiming(`queue;dur=1.5, infer;desc="acoustic, language";dur=120`, "cache")
+	t.Equal([]ServerTiming{
+		{Name: "queue", Duration: 1500 * time.Microsecond},
+		{Name: "infer", Duration: 120 * time.Millisecond, Description: "acoustic, language"},
+		{Name: "cache"},
+	}, timings)
+}
+
+func (t *TestUnitServerTimingSuite) TestInvalid() {
+	t.Empty(ParseServerTiming("", " , ;dur=1"))
+	t.Equal([]ServerTiming{{Name: "db", Description: `say "hi"`}},
+		ParseServerTiming(`db;dur=fast;desc="say \"hi\""`))
+}
@@ -0,0 +1,184 @@
+package common
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// speechRateFrameDuration 语速估计时计算能量的帧长，单位：秒
+	speechRateFrameDuration = 0.02
+	// speechRateHopDuration 语速估计时计算能量的帧移，单位：秒
+	speechRateHopDuration = 0.01
+	// speechRateMinPeakDistance 相邻两个音节峰值之间的最小间隔，单位：秒
+	speechRateMinPeakDistance = 0.08
+	// speechRateMaxPauseDuration 计入语音时长的音节间最长停顿，更长的停顿不计入，单位：秒
+	speechRateMaxPauseDuration = 0.3
+	// speechRateMinVoicedDuration 能够估计语速所需的最短有声时长，单位：秒
+	speechRateMinVoicedDuration = 0.5
+	// speechMinRegionDuration 语音活动检测中保留的最短语音区间，单位：秒
+	speechMinRegionDuration = 0.1
+)
+
+// EstimateSpeechRate 基于短时能量包络的峰值检测估计语速，单位：音节/秒
+//
+// 汉语基本上每个音节对应一个能量峰值，因此能量包络中显著峰值的个数除以有声段时长，
+// 即可近似得到语速。语音时长包含音节间的短停顿，但不包含长停顿。有声段过短时无法估计，返回0
+func (f *FloatBuffer) EstimateSpeechRate() float64 {
+	if f.FrameRate <= 0 || f.NumFrames() == 0 {
+		return 0
+	}
+
+	energy := frameEnergyDB(f.MixToMono().Samples[0], f.FrameRate)
+	if len(energy) < 3 {
+		return 0
+	}
+
+	// 平滑能量包络，滤除音节内部的小幅波动
+	smoothed := movingAverage(energy, 5)
+	threshold := voicedThreshold(smoothed)
+
+	speechDuration := voicedDuration(smoothed, threshold)
+	if speechDuration < speechRateMinVoicedDuration {
+		return 0
+	}
+
+	minDistance := int(speechRateMinPeakDistance / speechRateHopDuration)
+	peaks := 0
+	lastPeak := -minDistance
+	valley := smoothed[0]
+	for i := 1; i < len(smoothed)-1; i += 1 {
+		valley = math.Min(valley, smoothed[i])
+		isPeak := smoothed[i] > threshold && smoothed[i] >= smoothed[i-1] && smoothed[i] > smoothed[i+1]
+		// 峰值需要比前一个谷值高出至少3dB才被视为新的音节
+		if isPeak && smoothed[i]-valley >= 3 && i-lastPeak >= minDistance {
+			peaks += 1
+			lastPeak = i
+			valley = smoothed[i]
+		}
+	}
+
+	return float64(peaks) / speechDuration
+}
+
+// DetectSpeechRegions 基于短时能量的简单语音活动检测，返回有声区间，
+// 间隔小于300ms的相邻区间会被合并，短于100ms的区间会被丢弃
+func (f *FloatBuffer) DetectSpeechRegions() []Region {
+	if f.FrameRate <= 0 || f.NumFrames() == 0 {
+		return nil
+	}
+
+	energy := frameEnergyDB(f.MixToMono().Samples[0], f.FrameRate)
+	if len(energy) == 0 {
+		return nil
+	}
+	smoothed := movingAverage(energy, 5)
+	threshold := voicedThreshold(smoothed)
+
+	hop := time.Duration(speechRateHopDuration * float64(time.Second))
+	frameLen := time.Duration(speechRateFrameDuration * float64(time.Second))
+	maxPauseFrames := int(speechRateMaxPauseDuration / speechRateHopDuration)
+	minRegion := time.Duration(speechMinRegionDuration * float64(time.Second))
+
+	var regions []Region
+	start, last := -1, -1
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		region := Region{Start: time.Duration(start) * hop, End: time.Duration(last)*hop + frameLen}
+		if region.End-region.Start >= minRegion {
+			regions = append(regions, region)
+		}
+	}
+
+	for i, value := range smoothed {
+		if value <= threshold {
+			continue
+		}
+		if start >= 0 && i-last > maxPauseFrames {
+			flush()
+			start = -1
+		}
+		if start < 0 {
+			start = i
+		}
+		last = i
+	}
+	flush()
+
+	return regions
+}
+
+// voicedThreshold 计算有声阈值：取峰值以下25dB与噪声底(10%分位数)以上6dB中的较大者
+func voicedThreshold(energy []float64) float64 {
+	sorted := append([]float64(nil), energy...)
+	sort.Float64s(sorted)
+	noiseFloor := sorted[len(sorted)/10]
+	peak := sorted[len(sorted)-1]
+
+	return math.Max(peak-25, noiseFloor+6)
+}
+
+// voicedDuration 计算能量超过阈值的有声帧总时长，音节间的短停顿也计入其中，单位：秒
+func voicedDuration(energy []float64, threshold float64) float64 {
+	maxPauseFrames := int(speechRateMaxPauseDuration / speechRateHopDuration)
+	frames := 0
+	pause := 0
+	started := false
+	for _, value := range energy {
+		if value <= threshold {
+			pause += 1
+			continue
+		}
+
+		if started && pause <= maxPauseFrames {
+			frames += pause
+		}
+		frames += 1
+		pause = 0
+		started = true
+	}
+
+	return float64(frames) * speechRateHopDuration
+}
+
+// frameEnergyDB 计算分帧短时能量，单位：dB
+func frameEnergyDB(samples []float32, frameRate int) []float64 {
+	frameLen := int(float64(frameRate) * speechRateFrameDuration)
+	hop := int(float64(frameRate) * speechRateHopDuration)
+	if frameLen <= 0 || hop <= 0 || len(samples) < frameLen {
+		return nil
+	}
+
+	energy := make([]float64, 0, (len(samples)-frameLen)/hop+1)
+	for start := 0; start+frameLen <= len(samples); start += hop {
+		var sum float64
+		for _, value := range samples[start : start+frameLen] {
+			sum += float64(value) * float64(value)
+		}
+		energy = append(energy, 10*math.Log10(sum/float64(frameLen)+1e-10))
+	}
+
+	return energy
+}
+
+// movingAverage 计算窗长为 width 的滑动平均
+func movingAverage(values []float64, width int) []float64 {
+	result := make([]float64, len(values))
+	half := width / 2
+	for i := range values {
+		var sum float64
+		count := 0
+		for j := i - half; j <= i+half; j += 1 {
+			if j >= 0 && j < len(values) {
+				sum += values[j]
+				count += 1
+			}
+		}
+		result[i] = sum / float64(count)
+	}
+
+	return result
+}
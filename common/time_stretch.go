@@ -0,0 +1,133 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// wsolaWindowDuration WSOLA算法的分析窗长，单位：秒
+	wsolaWindowDuration = 0.03
+	// wsolaTolerance WSOLA算法寻找最佳拼接位置时的搜索范围，单位：秒
+	wsolaTolerance = 0.01
+)
+
+// TimeStretch 使用WSOLA(波形相似重叠相加)算法对音频做不改变音高的时间伸缩
+//
+// rate 为语速倍率，大于1时加快语速、时长变短，小于1时放慢语速、时长变长，
+// 输出时长约为原时长除以 rate。多声道音频的各声道使用相同的拼接位置，保持声道间对齐
+func (f *FloatBuffer) TimeStretch(rate float64) (*FloatBuffer, error) {
+	if rate <= 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return nil, fmt.Errorf("error: invalid time stretch rate `%f`", rate)
+	}
+	if f.FrameRate <= 0 {
+		return nil, fmt.Errorf("error: invalid frame rate `%d`", f.FrameRate)
+	}
+
+	numFrames := f.NumFrames()
+	windowLen := int(float64(f.FrameRate)*wsolaWindowDuration) / 2 * 2
+	if rate == 1 || numFrames < windowLen*2 || windowLen < 4 {
+		return f.Clone(), nil
+	}
+
+	synthesisHop := windowLen / 2
+	analysisHop := float64(synthesisHop) * rate
+	tolerance := int(float64(f.FrameRate) * wsolaTolerance)
+	window := hannWindow(windowLen)
+	mono := f.MixToMono().Samples[0]
+
+	outFrames := int(math.Ceil(float64(numFrames) / rate))
+	output := NewFloatBuffer(f.FrameRate, f.Channels(), outFrames+windowLen)
+	norm := make([]float32, outFrames+windowLen)
+
+	prevPos := 0
+	for k := 0; ; k += 1 {
+		synthesisPos := k * synthesisHop
+		nominalPos := int(math.Round(float64(k) * analysisHop))
+		if synthesisPos >= outFrames || nominalPos+windowLen > numFrames {
+			break
+		}
+
+		pos := nominalPos
+		if k > 0 {
+			pos = bestOverlapPosition(mono, prevPos+synthesisHop, nominalPos, tolerance, windowLen)
+		}
+
+		for i := range f.Samples {
+			src := f.Samples[i][pos : pos+windowLen]
+			dst := output.Samples[i][synthesisPos : synthesisPos+windowLen]
+			for n := range src {
+				dst[n] += src[n] * window[n]
+			}
+		}
+		for n := 0; n < windowLen; n += 1 {
+			norm[synthesisPos+n] += window[n]
+		}
+
+		prevPos = pos
+	}
+
+	for i := range output.Samples {
+		for n, weight := range norm {
+			if weight > 1e-3 {
+				output.Samples[i][n] /= weight
+			}
+		}
+	}
+
+	return output.Slice(0, outFrames), nil
+}
+
+// TimeStretch 对Wav对象做不改变音高的时间伸缩，参见 FloatBuffer.TimeStretch
+func (w *Wav) TimeStretch(rate float64) (Wav, error) {
+	buffer, err := w.ToFloatBuffer()
+	if err != nil {
+		return Wav{}, err
+	}
+
+	stretched, err := buffer.TimeStretch(rate)
+	if err != nil {
+		return Wav{}, err
+	}
+
+	return stretched.ToWav(), nil
+}
+
+// bestOverlapPosition 在 nominalPos 附近 tolerance 范围内寻找与 templatePos 处波形最相似的位置
+func bestOverlapPosition(samples []float32, templatePos int, nominalPos int, tolerance int, windowLen int) int {
+	numFrames := len(samples)
+	if templatePos+windowLen > numFrames {
+		return nominalPos
+	}
+
+	template := samples[templatePos : templatePos+windowLen]
+	bestPos := nominalPos
+	bestScore := math.Inf(-1)
+	for pos := nominalPos - tolerance; pos <= nominalPos+tolerance; pos += 1 {
+		if pos < 0 || pos+windowLen > numFrames {
+			continue
+		}
+
+		var score float64
+		candidate := samples[pos : pos+windowLen]
+		for n := range template {
+			score += float64(template[n] * candidate[n])
+		}
+		if score > bestScore {
+			bestScore = score
+			bestPos = pos
+		}
+	}
+
+	return bestPos
+}
+
+// hannWindow 生成长度为 length 的周期汉宁窗，50%重叠时各窗之和为常数
+func hannWindow(length int) []float32 {
+	window := make([]float32, length)
+	for n := range window {
+		window[n] = float32(0.5 - 0.5*math.Cos(2*math.Pi*float64(n)/float64(length)))
+	}
+
+	return window
+}
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitTimeStretch(t *testing.T) {
+	suite.Run(t, new(TestUnitTimeStretchSuite))
+}
+
+type TestUnitTimeStretchSuite struct {
+	suite.Suite
+}
+
+// zeroCrossings 统计过零次数，用于粗略比较音高
+func zeroCrossings(samples []float32) int {
+	count := 0
+	for i := 1; i < len(samples); i += 1 {
+		if (samples[i-1] < 0) != (samples[i] < 0) {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+func (t *TestUnitTimeStretchSuite) TestTimeStretch() {
+	buffer := NewFloatBuffer(16000, 1, 16000)
+	for n := range buffer.Samples[0] {
+		buffer.Samples[0][n] = float32(0.5 * math.Sin(2*math.Pi*440*float64(n)/16000))
+	}
+
+	for _, rate := range []float64{0.5, 0.8, 1.25, 2} {
+		stretched, err := buffer.TimeStretch(rate)
+		t.Equal(true, err == nil)
+		t.Equal(int(math.Ceil(16000/rate)), stretched.NumFrames())
+
+		// 音高不变：单位时间内的过零次数应与原始音频接近
+		body := stretched.Slice(800, stretched.NumFrames()-800)
+		perSecond := float64(zeroCrossings(body.Samples[0])) * 16000 / float64(body.NumFrames())
+		t.InDelta(880, perSecond, 30)
+	}
+
+	_, err := buffer.TimeStretch(0)
+	t.Equal(true, err != nil)
+}
+
+func (t *TestUnitTimeStretchSuite) TestEstimateSpeechRate() {
+	// 模拟每秒4个音节的语音：200ms的音节接50ms的间隙
+	buffer := NewFloatBuffer(16000, 1, 16000*3)
+	for n := range buffer.Samples[0] {
+		position := n % 4000
+		if position < 3200 {
+			envelope := math.Sin(math.Pi * float64(position) / 3200)
+			buffer.Samples[0][n] = float32(0.5 * envelope * math.Sin(2*math.Pi*200*float64(n)/16000))
+		}
+	}
+
+	rate := buffer.EstimateSpeechRate()
+	t.InDelta(4, rate, 0.6)
+
+	silence := NewFloatBuffer(16000, 1, 16000)
+	t.Equal(0.0, silence.EstimateSpeechRate())
+}
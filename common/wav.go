@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 // Wav Wav格式结构对象
+//
+// 采样数据以交错排列的小端16bit PCM字节序列形式保存，与ASRT接口所需的原始数据格式一致，
+// 因此 GetRawSamples 和 SamplesReader 不需要任何复制或转换
 type Wav struct {
-	// Samples 采样样本数据
+	// Samples 按声道拆分的采样样本数据
+	//
+	// Deprecated: 仅为兼容旧版本保留，请改用 GetSamples 和 SetSamples。
+	// 除 NewBlankWav 初始化为各声道的空切片外，任何方法都不会填充或同步修改该字段；
+	// 该字段中有采样数据时，Serialize 使用它代替Wav内部的采样数据
 	Samples [][]int16
 	// FrameRate 采样频率，单位：Hz。例如：16000 / 8000 等
 	FrameRate int
@@ -18,7 +26,8 @@ type Wav struct {
 	SampleWidth int
 	// BytesPerSec 比特率，单位：bps
 	BytesPerSec int
-	wavByteData []byte
+	// data 交错排列的小端PCM采样数据
+	data []byte
 
 	blocklenSample uint16
 	bitNum         uint16
@@ -36,6 +45,7 @@ func NewBlankWav(frameRate int, channels int, sampleWidth int) Wav {
 		SampleWidth: sampleWidth,
 		BytesPerSec: frameRate * channels * sampleWidth,
 		Samples:     make([][]int16, channels),
+		data:        make([]byte, 0),
 	}
 
 	for i := 0; i < channels; i += 1 {
@@ -44,24 +54,40 @@ func NewBlankWav(frameRate int, channels int, sampleWidth int) Wav {
 	return wave
 }
 
-// Deserialize Wave格式反序列化
+// NewWavFromRawSamples 使用交错排列的小端16bit PCM原始采样数据构造Wav对象，
+// 末尾不足一帧的数据会被忽略；rawSamples 不会被复制，调用方在此之后不应再修改它
+func NewWavFromRawSamples(rawSamples []byte, frameRate int, channels int, sampleWidth int) (Wav, error) {
+	if sampleWidth != 2 {
+		return Wav{}, fmt.Errorf("error: unsupport wave sample width `%d`", sampleWidth)
+	}
+	if channels < 1 {
+		return Wav{}, fmt.Errorf("error: invalid wave channels number `%d`", channels)
+	}
+
+	wave := NewBlankWav(frameRate, channels, sampleWidth)
+	n := len(rawSamples) / wave.blockAlign() * wave.blockAlign()
+	// 限制容量，使后续的追加操作分配新的底层数组，不会改写调用方 rawSamples 之后的数据
+	wave.data = rawSamples[:n:n]
+
+	return wave, nil
+}
+
+// Deserialize Wave格式反序列化，采样数据会被复制一份，不会持有 bytesData 的引用
 func (w *Wav) Deserialize(bytesData []byte) error {
 	if bytesData == nil {
 		return fmt.Errorf("error: byte array is nil")
 	}
 
-	w.wavByteData = bytesData
-	bodyLength, p, err := w.parseHeader()
+	bodyLength, p, err := w.parseHeader(bytesData)
 	if err != nil {
 		return err
 	}
 
-	err = w.parseBody(p, bodyLength)
-	return err
+	return w.parseBody(bytesData, p, bodyLength)
 }
 
 // parseHeader 解码头部
-func (w *Wav) parseHeader() (bodyLength uint32, startPosition uint32, err error) {
+func (w *Wav) parseHeader(wavByteData []byte) (bodyLength uint32, startPosition uint32, err error) {
 	var riff uint32       // 4 byte
 	var riffSize uint32   // 4 byte
 	var waveID uint32     // 4 byte
@@ -82,117 +108,141 @@ func (w *Wav) parseHeader() (bodyLength uint32, startPosition uint32, err error)
 
 	var p uint32 = 0
 
-	riff = binary.BigEndian.Uint32((w.wavByteData)[p : p+4])
+	if len(wavByteData) < 44 {
+		return 0, p, fmt.Errorf("error: this file is too short to be a wave file")
+	}
+
+	riff = binary.BigEndian.Uint32(wavByteData[p : p+4])
 	p += 4
 	if riff != 0x52494646 {
 		return 0, p, fmt.Errorf("error: this file is not riff format")
 	}
 
-	riffSize = binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // 文件剩余长度
+	riffSize = binary.LittleEndian.Uint32(wavByteData[p : p+4]) // 文件剩余长度
 	p += 4
-	if riffSize != uint32(len(w.wavByteData))-p {
+	if riffSize != uint32(len(wavByteData))-p {
 		return 0, p, fmt.Errorf("error: this file maybe has been destroyed so that file length not equals flag value")
 	}
 
-	waveID = binary.BigEndian.Uint32((w.wavByteData)[p : p+4]) // wave文件标识
+	waveID = binary.BigEndian.Uint32(wavByteData[p : p+4]) // wave文件标识
 	p += 4
 	if waveID != 0x57415645 {
 		return 0, p, fmt.Errorf("error: this file is not wave file")
 	}
 
-	tmp := binary.BigEndian.Uint32((w.wavByteData)[p : p+4]) // 4 byte
+	tmp := binary.BigEndian.Uint32(wavByteData[p : p+4]) // 4 byte
 	p += 4
 	switch tmp {
 	case 0x4A554E4B: // 发现了junk flag，这个值是 junkID
-		junklength = binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // junk长度
+		junklength = binary.LittleEndian.Uint32(wavByteData[p : p+4]) // junk长度
 		p += 4
+		// 跳过junk部分后至少还需要fmt标记、fmt头部和data标记共32字节，使用64位计算避免溢出
+		if uint64(p)+uint64(junklength)+32 > uint64(len(wavByteData)) {
+			return 0, p, fmt.Errorf("error: wave junk chunk length `%d` exceeds file length", junklength)
+		}
 		p += junklength // 将不要的junk部分跳过
 
-		_ = binary.BigEndian.Uint32((w.wavByteData)[p : p+4]) // 读fmt 标记: fmtID
+		_ = binary.BigEndian.Uint32(wavByteData[p : p+4]) // 读fmt 标记: fmtID
 		p += 4
 	case 0x666D7420: // 发现了fmt flag，这个值是 fmtID
-		_ = tmp // fmtID
+		_ = tmp // fmtID，文件至少44字节，足够读取之后的fmt头部和data标记
 	default:
 		return 0, p, fmt.Errorf("error: can not find any junk or fmt flag in this wave file")
 	}
 
-	w.cksize = binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // 4 byte，小端存储
+	w.cksize = binary.LittleEndian.Uint32(wavByteData[p : p+4]) // 4 byte，小端存储
 	p += 4
 	pDataStart := cksize
 	_ = pDataStart + 8
 
-	tmpWaveType := binary.LittleEndian.Uint16((w.wavByteData)[p : p+2]) // 2 byte，这个字段是小端存储
+	tmpWaveType := binary.LittleEndian.Uint16(wavByteData[p : p+2]) // 2 byte，这个字段是小端存储
 	p += 2
 	waveType = int(tmpWaveType)
 	if waveType != 1 {
 		return 0, p, fmt.Errorf("error: this wave file is not pcm format and it is not supported")
 	}
 
-	channel = binary.LittleEndian.Uint16((w.wavByteData)[p : p+2]) // 声道数 2 byte，小端存储
+	channel = binary.LittleEndian.Uint16(wavByteData[p : p+2]) // 声道数 2 byte，小端存储
 	p += 2
 	w.Channels = int(channel)
 
-	sampleRate = binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // 采样频率，小端存储
+	sampleRate = binary.LittleEndian.Uint32(wavByteData[p : p+4]) // 采样频率，小端存储
 	p += 4
 	w.FrameRate = int(sampleRate)
 
-	bytespersec = binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // 每秒钟字节数，小端存储
+	bytespersec = binary.LittleEndian.Uint32(wavByteData[p : p+4]) // 每秒钟字节数，小端存储
 	p += 4
 	w.BytesPerSec = int(bytespersec)
 
-	w.blocklenSample = binary.LittleEndian.Uint16((w.wavByteData)[p : p+2]) // 每次采样的字节大小，2为单声道，4为立体声道，小端存储
+	w.blocklenSample = binary.LittleEndian.Uint16(wavByteData[p : p+2]) // 每次采样的字节大小，2为单声道，4为立体声道，小端存储
 	p += 2
 
-	w.bitNum = binary.LittleEndian.Uint16((w.wavByteData)[p : p+2]) // 每个声道的采样精度，默认16bit，小端存储
+	w.bitNum = binary.LittleEndian.Uint16(wavByteData[p : p+2]) // 每个声道的采样精度，默认16bit，小端存储
 	w.SampleWidth = int(w.bitNum) / 8
 	p += 2
 
-	tmp1 := binary.BigEndian.Uint16((w.wavByteData)[p : p+2])
+	tmp1 := binary.BigEndian.Uint16(wavByteData[p : p+2])
 	p += 2
 	for tmp1 != 0x6461 { // 寻找da标记
-		tmp1 = binary.BigEndian.Uint16((w.wavByteData)[p : p+2])
+		if int(p)+8 > len(wavByteData) {
+			return 0, p, fmt.Errorf("error: can not find `data` flag in wave file")
+		}
+		tmp1 = binary.BigEndian.Uint16(wavByteData[p : p+2])
 		p += 2
 	}
-	tmp1 = binary.BigEndian.Uint16((w.wavByteData)[p : p+2])
+	tmp1 = binary.BigEndian.Uint16(wavByteData[p : p+2])
 	p += 2
 	if tmp1 != 0x7461 { // ta标记
 		return 0, p, fmt.Errorf("error: can not find `data` flag in wave file")
 	}
 
-	dataSize := binary.LittleEndian.Uint32((w.wavByteData)[p : p+4]) // wav数据byte长度，小端存储
+	dataSize := binary.LittleEndian.Uint32(wavByteData[p : p+4]) // wav数据byte长度，小端存储
 	p += 4
 	if dataSize < 2 {
-		dataSize = uint32(len(w.wavByteData)) - p
+		dataSize = uint32(len(wavByteData)) - p
 	}
 
 	return dataSize, p, nil
 }
 
 // parseBody 解码数据区
-func (w *Wav) parseBody(startPosition uint32, bodyLength uint32) error {
-	p := startPosition
-	numSamples := bodyLength / uint32(w.blocklenSample) // 计算样本数
-
-	w.Samples = make([][]int16, w.Channels, numSamples)
-	for i := 0; i < int(numSamples); i += 1 {
-		for j := 0; j < w.Channels; j += 1 {
-			// 读入2字节有符号整数
-			bytesBuffer := bytes.NewBuffer(w.wavByteData[p : p+2])
-			var tmpSample int16
-			binary.Read(bytesBuffer, binary.LittleEndian, &tmpSample)
-			p += uint32(w.bitNum / 8)
-			w.Samples[j] = append(w.Samples[j], tmpSample)
-		}
+func (w *Wav) parseBody(wavByteData []byte, startPosition uint32, bodyLength uint32) error {
+	if w.bitNum != 16 {
+		return fmt.Errorf("error: unsupport wave sample bit width `%d`", w.bitNum)
+	}
+	if w.Channels == 0 {
+		return fmt.Errorf("error: wave channels number is zero")
+	}
+
+	blockAlign := uint32(w.blockAlign())
+	numSamples := bodyLength / blockAlign // 计算样本数
+	length := numSamples * blockAlign
+	if uint64(startPosition)+uint64(length) > uint64(len(wavByteData)) {
+		return fmt.Errorf("error: wave data length `%d` exceeds file length", bodyLength)
 	}
 
+	// 复制一份数据区，使得解码后的对象不再引用原始文件字节数组
+	w.data = make([]byte, length)
+	copy(w.data, wavByteData[startPosition:startPosition+length])
+
 	return nil
 }
 
-// Serialize Wave格式序列化
+// Serialize Wave格式序列化，已弃用的 Samples 字段中有采样数据时序列化该字段中的数据
 func (w *Wav) Serialize() ([]byte, error) {
-	waveData := w.packWave()
+	wave := w
+	if w.hasDeprecatedSamples() {
+		// 兼容直接修改 Samples 字段构造的Wav对象，不改变Wav内部的采样数据
+		legacy := *w
+		if err := legacy.SetSamples(w.Samples); err != nil {
+			return nil, err
+		}
+		wave = &legacy
+	}
 
-	res, err := w.packRiff(waveData)
+	waveData := wave.packWave()
+
+	res, err := wave.packRiff(waveData)
 	if err != nil {
 		return nil, err
 	}
@@ -200,10 +250,22 @@ func (w *Wav) Serialize() ([]byte, error) {
 	return res, nil
 }
 
+// hasDeprecatedSamples 判断已弃用的 Samples 字段中是否有采样数据
+func (w *Wav) hasDeprecatedSamples() bool {
+	for _, samples := range w.Samples {
+		if len(samples) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // packWave 打包wave部分
 func (w *Wav) packWave() []byte {
 	var byteBuf bytes.Buffer
 	var tmpBytes []byte
+	byteBuf.Grow(36 + len(w.data))
 	// waveID
 	tmpBytes = make([]byte, 4)
 	binary.BigEndian.PutUint32(tmpBytes, 0x57415645)
@@ -240,33 +302,105 @@ func (w *Wav) packWave() []byte {
 	binary.BigEndian.PutUint32(tmpBytes, 0x64617461)
 	byteBuf.Write(tmpBytes)
 	// data length (byte)
-	binary.LittleEndian.PutUint32(tmpBytes, uint32(len(w.Samples)*len(w.Samples[0])*2))
+	binary.LittleEndian.PutUint32(tmpBytes, uint32(len(w.data)))
 	byteBuf.Write(tmpBytes)
 
 	// wave data
-	tmpBytes = make([]byte, 2)
-	for j := 0; j < len(w.Samples[0]); j += 1 {
-		for i := 0; i < len(w.Samples); i += 1 {
-			binary.LittleEndian.PutUint16(tmpBytes, uint16(w.Samples[i][j]))
-			byteBuf.Write(tmpBytes)
-		}
-	}
+	byteBuf.Write(w.data)
 
 	return byteBuf.Bytes()
 }
 
 // GetRawSamples 读取Wave格式的Samples原始数据
+//
+// 返回的切片直接引用Wav内部的存储，不产生复制，调用方不应修改其内容；
+// 对返回切片做append操作总是会分配新的底层数组，不会影响Wav本身
 func (w *Wav) GetRawSamples() []byte {
-	var byteBuf bytes.Buffer
-	// wave data
-	tmpBytes := make([]byte, 2)
-	for j := 0; j < len(w.Samples[0]); j += 1 {
-		for i := 0; i < len(w.Samples); i += 1 {
-			binary.LittleEndian.PutUint16(tmpBytes, uint16(w.Samples[i][j]))
-			byteBuf.Write(tmpBytes)
+	return w.data[:len(w.data):len(w.data)]
+}
+
+// SamplesReader 获取一个读取原始采样数据的Reader，同时实现了 io.Reader、io.WriterTo 和 io.Seeker
+func (w *Wav) SamplesReader() *bytes.Reader {
+	return bytes.NewReader(w.data)
+}
+
+// WriteTo 将原始采样数据写入 writer，实现 io.WriterTo 接口
+func (w *Wav) WriteTo(writer io.Writer) (int64, error) {
+	n, err := writer.Write(w.data)
+	return int64(n), err
+}
+
+// NumFrames 获取每个声道的采样点数
+func (w *Wav) NumFrames() int {
+	blockAlign := w.blockAlign()
+	if blockAlign == 0 {
+		return 0
+	}
+
+	return len(w.data) / blockAlign
+}
+
+// Sample 读取指定声道第 index 个采样点的值
+func (w *Wav) Sample(channel int, index int) int16 {
+	p := index*w.blockAlign() + channel*w.SampleWidth
+	return int16(binary.LittleEndian.Uint16(w.data[p : p+2]))
+}
+
+// SetSample 设置指定声道第 index 个采样点的值
+func (w *Wav) SetSample(channel int, index int, value int16) {
+	p := index*w.blockAlign() + channel*w.SampleWidth
+	binary.LittleEndian.PutUint16(w.data[p:p+2], uint16(value))
+}
+
+// GetSamples 按声道拆分读取全部采样数据，返回的数据是一份新的复制
+func (w *Wav) GetSamples() [][]int16 {
+	numFrames := w.NumFrames()
+	samples := make([][]int16, w.Channels)
+	for i := 0; i < w.Channels; i += 1 {
+		samples[i] = make([]int16, numFrames)
+	}
+
+	for j := 0; j < numFrames; j += 1 {
+		for i := 0; i < w.Channels; i += 1 {
+			samples[i][j] = w.Sample(i, j)
 		}
 	}
-	return byteBuf.Bytes()
+
+	return samples
+}
+
+// SetSamples 使用按声道拆分的采样数据替换全部采样数据，各声道长度必须一致
+func (w *Wav) SetSamples(samples [][]int16) error {
+	if len(samples) != w.Channels {
+		return fmt.Errorf("error: samples's channel count `%d` not equals wav's `%d`", len(samples), w.Channels)
+	}
+	if w.SampleWidth != 2 {
+		return fmt.Errorf("error: unsupport wave sample width `%d`", w.SampleWidth)
+	}
+
+	numFrames := 0
+	if len(samples) > 0 {
+		numFrames = len(samples[0])
+	}
+	for i := 1; i < len(samples); i += 1 {
+		if len(samples[i]) != numFrames {
+			return fmt.Errorf("error: samples's shape is not aligned")
+		}
+	}
+
+	w.data = make([]byte, numFrames*w.blockAlign())
+	for j := 0; j < numFrames; j += 1 {
+		for i := 0; i < w.Channels; i += 1 {
+			w.SetSample(i, j, samples[i][j])
+		}
+	}
+
+	return nil
+}
+
+// blockAlign 每个采样帧的字节数
+func (w *Wav) blockAlign() int {
+	return w.Channels * w.SampleWidth
 }
 
 // packRiff 打包wave数据为riff格式文件
@@ -308,16 +442,11 @@ func (w *Wav) AppendWav(wavAppended Wav) error {
 			"error: appended wav's sample width not equals this wav's. this wav's is %d but apeended wav's is %d",
 			w.SampleWidth, wavAppended.SampleWidth)
 	}
-	if len(w.Samples) != len(wavAppended.Samples) {
-		return fmt.Errorf("error: appended wav samples's shape not equals this samples's")
-	}
-	if len(w.Samples) == 0 || len(wavAppended.Samples) == 0 {
+	if w.Channels == 0 {
 		return fmt.Errorf("error: wav samples's shape is zero")
 	}
 
-	for i := 0; i < len(w.Samples); i += 1 {
-		w.Samples[i] = append(w.Samples[i], wavAppended.Samples[i]...)
-	}
+	w.data = append(w.data, wavAppended.data...)
 
 	return nil
 }
@@ -325,9 +454,6 @@ func (w *Wav) AppendWav(wavAppended Wav) error {
 /* AppendBlank 在wave的后面追加一定时间的静音区，单位：毫秒。
 这里用无符号类型是因为不允许添加负数时间长度 */
 func (w *Wav) AppendBlank(millisecond uint32) {
-	zeroByteCount := millisecond * uint32(w.FrameRate/1000)
-	for i := 0; i < w.Channels; i += 1 {
-		arr1 := make([]int16, zeroByteCount)
-		w.Samples[i] = append(w.Samples[i], arr1...)
-	}
+	zeroFrameCount := int(millisecond) * w.FrameRate / 1000
+	w.data = append(w.data, make([]byte, zeroFrameCount*w.blockAlign())...)
 }
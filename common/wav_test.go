@@ -37,12 +37,7 @@ func (t *TestUnitWavSuite) TestNewBlankWav() {
 	t.Equal(2, wave.SampleWidth)
 	t.Equal(32000, wave.BytesPerSec)
 
-	wb := readBinFile("../testData/data1.wav")
-	w := Wav{}
-	err := w.Deserialize(wb)
-	t.Equal(true, err == nil)
-
-	wave.AppendWav(w)
+	wave.AppendWav(fixtureWav())
 	wb2, err := wave.Serialize()
 	t.Equal(true, err == nil)
 
@@ -57,24 +52,24 @@ func (t *TestUnitWavSuite) TestNewBlankWav() {
 func (t *TestUnitWavSuite) TestDeserialize() {
 	tests := []struct {
 		name     string
-		waveFile string
+		wavBytes []byte
 		want     bool
 	}{
 		{
 			name:     "success",
-			waveFile: "../testData/data1.wav",
+			wavBytes: fixtureWavBytes(),
 			want:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func() {
-			wavBytes := readBinFile(tt.waveFile)
+			wavBytes := tt.wavBytes
 			fmt.Println("waveBytes的长度:", len(wavBytes))
 
 			wave := Wav{}
 			err := wave.Deserialize(wavBytes)
-			fmt.Println("wav类:", wave.BytesPerSec, wave.Channels, wave.FrameRate, wave.SampleWidth, wave.Samples[0][0:100])
+			fmt.Println("wav类:", wave.BytesPerSec, wave.Channels, wave.FrameRate, wave.SampleWidth, wave.GetSamples()[0][0:100])
 
 			log.Println(err)
 			t.Equal(tt.want, err == nil)
@@ -85,29 +80,29 @@ func (t *TestUnitWavSuite) TestDeserialize() {
 func (t *TestUnitWavSuite) TestSerialize() {
 	tests := []struct {
 		name     string
-		waveFile string
+		wavBytes []byte
 		want     bool
 	}{
 		{
 			name:     "success",
-			waveFile: "../testData/data1.wav",
+			wavBytes: fixtureWavBytes(),
 			want:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func() {
-			wavBytes := readBinFile(tt.waveFile)
+			wavBytes := tt.wavBytes
 			fmt.Println("waveBytes的长度:", len(wavBytes))
 
 			wave := Wav{}
 			_ = wave.Deserialize(wavBytes)
-			fmt.Println("wav类:", wave.BytesPerSec, wave.Channels, wave.FrameRate, wave.SampleWidth, wave.Samples[0][0:100])
+			fmt.Println("wav类:", wave.BytesPerSec, wave.Channels, wave.FrameRate, wave.SampleWidth, wave.GetSamples()[0][0:100])
 
 			waveBytesNew, _ := wave.Serialize()
 			_ = writeBinFile("../testData/tmp.wav", waveBytesNew)
 
-			wavBytesNew2 := readBinFile(tt.waveFile)
+			wavBytesNew2 := readBinFile("../testData/tmp.wav")
 			fmt.Println("waveBytes的长度:", len(wavBytesNew2))
 
 			waveNew := Wav{}
@@ -122,25 +117,25 @@ func (t *TestUnitWavSuite) TestSerialize() {
 func (t *TestUnitWavSuite) TestAppendWav() {
 	tests := []struct {
 		name     string
-		waveFile string
+		wavBytes []byte
 		want     bool
 	}{
 		{
 			name:     "success",
-			waveFile: "../testData/data1.wav",
+			wavBytes: fixtureWavBytes(),
 			want:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func() {
-			wavBytes := readBinFile(tt.waveFile)
+			wavBytes := tt.wavBytes
 			fmt.Println("waveBytes的长度:", len(wavBytes))
 
 			wave1 := Wav{}
 			err := wave1.Deserialize(wavBytes)
 			t.Equal(tt.want, err == nil)
-			fmt.Println("wav类:", wave1.BytesPerSec, wave1.Channels, wave1.FrameRate, wave1.SampleWidth, wave1.Samples[0][0:100])
+			fmt.Println("wav类:", wave1.BytesPerSec, wave1.Channels, wave1.FrameRate, wave1.SampleWidth, wave1.GetSamples()[0][0:100])
 
 			wave2 := Wav{}
 			err = wave2.Deserialize(wavBytes)
@@ -160,25 +155,25 @@ func (t *TestUnitWavSuite) TestAppendWav() {
 func (t *TestUnitWavSuite) TestAppendBlank() {
 	tests := []struct {
 		name     string
-		waveFile string
+		wavBytes []byte
 		want     bool
 	}{
 		{
 			name:     "success",
-			waveFile: "../testData/data1.wav",
+			wavBytes: fixtureWavBytes(),
 			want:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func() {
-			wavBytes := readBinFile(tt.waveFile)
+			wavBytes := tt.wavBytes
 			fmt.Println("waveBytes的长度:", len(wavBytes))
 
 			wave1 := Wav{}
 			err := wave1.Deserialize(wavBytes)
 			t.Equal(tt.want, err == nil)
-			fmt.Println("wav类:", wave1.BytesPerSec, wave1.Channels, wave1.FrameRate, wave1.SampleWidth, wave1.Samples[0][0:100])
+			fmt.Println("wav类:", wave1.BytesPerSec, wave1.Channels, wave1.FrameRate, wave1.SampleWidth, wave1.GetSamples()[0][0:100])
 
 			wave2 := Wav{}
 			err = wave2.Deserialize(wavBytes)
@@ -196,6 +191,178 @@ func (t *TestUnitWavSuite) TestAppendBlank() {
 	}
 }
 
+func (t *TestUnitWavSuite) TestRawSamples() {
+	wavBytes := fixtureWavBytes()
+	wave := Wav{}
+	err := wave.Deserialize(wavBytes)
+	t.Equal(true, err == nil)
+
+	// 解码后的对象不应再引用原始字节数组
+	raw := wave.GetRawSamples()
+	first := wave.Sample(0, 0)
+	for i := range wavBytes {
+		wavBytes[i] = 0xFF
+	}
+	t.Equal(first, wave.Sample(0, 0))
+
+	// GetRawSamples 不复制数据
+	t.True(&raw[0] == &wave.GetRawSamples()[0])
+	t.Equal(wave.NumFrames()*wave.Channels*wave.SampleWidth, len(raw))
+
+	// 对返回值append不会改写Wav内部存储
+	appended := append(raw, 1, 2)
+	t.True(&raw[0] != &appended[0])
+
+	var buf bytes.Buffer
+	n, err := wave.WriteTo(&buf)
+	t.Equal(true, err == nil)
+	t.Equal(int64(len(raw)), n)
+	t.Equal(raw, buf.Bytes())
+
+	buf.Reset()
+	_, err = buf.ReadFrom(wave.SamplesReader())
+	t.Equal(true, err == nil)
+	t.Equal(raw, buf.Bytes())
+
+	samples := wave.GetSamples()
+	t.Equal(wave.NumFrames(), len(samples[0]))
+	wave2 := NewBlankWav(wave.FrameRate, wave.Channels, wave.SampleWidth)
+	err = wave2.SetSamples(samples)
+	t.Equal(true, err == nil)
+	t.Equal(raw, wave2.GetRawSamples())
+
+	wave3, err := NewWavFromRawSamples(raw, wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.Nil(err)
+	t.Equal(wave.NumFrames(), wave3.NumFrames())
+	t.Equal(samples[0][10], wave3.Sample(0, 10))
+}
+
+// rawWav 使用原始采样数据构造Wav对象，构造失败时测试失败
+func (t *TestUnitWavSuite) rawWav(rawSamples []byte, channels int) Wav {
+	wave, err := NewWavFromRawSamples(rawSamples, 16000, channels, 2)
+	t.Require().Nil(err)
+
+	return wave
+}
+
+func (t *TestUnitWavSuite) TestRawSamplesCapacity() {
+	backing := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+	wave := t.rawWav(backing[:4], 1)
+	wave.AppendBlank(1)
+	t.Nil(wave.AppendWav(t.rawWav([]byte{9, 0}, 1)))
+
+	// 追加数据不会改写调用方切片之后的数据
+	t.Equal([]byte{1, 0, 2, 0, 3, 0, 4, 0}, backing)
+	t.Equal(19, wave.NumFrames())
+}
+
+func (t *TestUnitWavSuite) TestRawSamplesInvalid() {
+	for _, sampleWidth := range []int{0, 1, 3, 4} {
+		_, err := NewWavFromRawSamples(make([]byte, 12), 16000, 1, sampleWidth)
+		t.Error(err, sampleWidth)
+	}
+	_, err := NewWavFromRawSamples(make([]byte, 12), 16000, 0, 2)
+	t.Error(err)
+
+	// 末尾不足一帧的数据被忽略
+	mono := t.rawWav([]byte{1, 0, 2, 0, 3, 0, 4}, 1)
+	t.Equal(3, mono.NumFrames())
+	stereo := t.rawWav([]byte{1, 0, 2, 0, 3, 0}, 2)
+	t.Equal(1, stereo.NumFrames())
+}
+
+func (t *TestUnitWavSuite) TestDeserializeTruncated() {
+	wave := t.rawWav([]byte{1, 0, 2, 0, 3, 0, 4, 0}, 1)
+	wavBytes, err := wave.Serialize()
+	t.Nil(err)
+
+	// 头部声明的数据长度大于文件实际长度
+	binary.LittleEndian.PutUint32(wavBytes[40:44], 1000)
+	w := Wav{}
+	t.Error(w.Deserialize(wavBytes))
+}
+
+// junkWavBytes 构造一个带有 junk 块的wave文件，junk 块声明的长度为 junkLength，实际长度为 actual
+func junkWavBytes(junkLength uint32, actual int) []byte {
+	wave := NewBlankWav(16000, 1, 2)
+	wave.AppendBlank(1)
+	wavBytes, _ := wave.Serialize()
+
+	junk := make([]byte, 8+actual)
+	binary.BigEndian.PutUint32(junk[0:4], 0x4A554E4B)
+	binary.LittleEndian.PutUint32(junk[4:8], junkLength)
+	result := append(append(append([]byte{}, wavBytes[:12]...), junk...), wavBytes[12:]...)
+	binary.LittleEndian.PutUint32(result[4:8], uint32(len(result)-8))
+
+	return result
+}
+
+func (t *TestUnitWavSuite) TestDeserializeJunk() {
+	w := Wav{}
+	t.Nil(w.Deserialize(junkWavBytes(6, 6)))
+	t.Equal(16, w.NumFrames())
+
+	// junk 块声明的长度超出文件时返回错误而不是panic
+	for _, junkLength := range []uint32{7, 40, 1000, 0x7FFFFFFF, 0xFFFFFF00, 0xFFFFFFFF} {
+		t.NotPanics(func() {
+			t.Error(w.Deserialize(junkWavBytes(junkLength, 6)), junkLength)
+		})
+	}
+
+	// 截断在任意位置的文件都不会panic
+	wavBytes := junkWavBytes(6, 6)
+	for n := 0; n < len(wavBytes); n += 1 {
+		truncated := append([]byte{}, wavBytes[:n]...)
+		if n >= 8 {
+			binary.LittleEndian.PutUint32(truncated[4:8], uint32(n-8))
+		}
+		t.NotPanics(func() {
+			_ = w.Deserialize(truncated)
+		}, n)
+	}
+}
+
+func (t *TestUnitWavSuite) TestDeprecatedSamples() {
+	wave := t.rawWav([]byte{1, 0, 2, 0, 3, 0, 4, 0}, 2)
+	wavBytes, err := wave.Serialize()
+	t.Nil(err)
+
+	// 解码时不再填充 Samples，避免额外复制一份采样数据
+	w := Wav{}
+	t.Nil(w.Deserialize(wavBytes))
+	t.Nil(w.Samples)
+	t.Equal([][]int16{{1, 3}, {2, 4}}, w.GetSamples())
+
+	// 通过 NewBlankWav 和 Samples 字段构造Wav对象的旧用法
+	old := NewBlankWav(16000, 2, 2)
+	old.Samples[0] = append(old.Samples[0], 1, 3)
+	old.Samples[1] = append(old.Samples[1], 2, 4)
+	oldBytes, err := old.Serialize()
+	t.Nil(err)
+	t.Equal(wavBytes, oldBytes)
+	t.Equal(0, old.NumFrames())
+
+	// Samples 中有数据时 Serialize 总是使用它，不会被忽略
+	w.Samples = [][]int16{{5}, {6}}
+	modified, err := w.Serialize()
+	t.Nil(err)
+	decoded := Wav{}
+	t.Nil(decoded.Deserialize(modified))
+	t.Equal([][]int16{{5}, {6}}, decoded.GetSamples())
+	t.Equal([][]int16{{1, 3}, {2, 4}}, w.GetSamples())
+
+	// Samples 为空时使用追加后的内部数据
+	blank := NewBlankWav(16000, 2, 2)
+	t.Nil(blank.AppendWav(wave))
+	blankBytes, err := blank.Serialize()
+	t.Nil(err)
+	t.Equal(wavBytes, blankBytes)
+
+	w.Samples = [][]int16{{1, 2}}
+	_, err = w.Serialize()
+	t.Error(err)
+}
+
 func TestUnitDefault(t *testing.T) {
 	var byteBuf bytes.Buffer
 	var tmpBytes []byte
@@ -0,0 +1,122 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// defaultAPIVersionHeader 默认携带API版本的请求头
+	defaultAPIVersionHeader = "X-ASRT-API-Version"
+	// apiVersionPlaceholder 接口路径中表示API版本的占位符
+	apiVersionPlaceholder = "{version}"
+)
+
+// HTTPPaths HTTP协议各接口的路径，拼接在 SubPath 之后，为空时使用默认路径，
+// 路径中的 {version} 会被替换为 APIVersion.Version，例如 "/v{version}/recognize"，
+// 使用占位符时必须设置版本号；路径不能包含查询参数，需要时请使用 APIVersion.Query
+type HTTPPaths struct {
+	// All 语音识别接口路径，默认 "/all"
+	All string `json:"all" yaml:"all"`
+	// Speech 声学模型接口路径，默认 "/speech"
+	Speech string `json:"speech" yaml:"speech"`
+	// Language 语言模型接口路径，默认 "/language"
+	Language string `json:"language" yaml:"language"`
+}
+
+// path 获取操作 op 对应的接口路径
+func (p HTTPPaths) path(op string) string {
+	var path string
+	switch op {
+	case "all":
+		path = p.All
+	case "speech":
+		path = p.Speech
+	case "language":
+		path = p.Language
+	}
+	if path == "" {
+		path = "/" + op
+	}
+
+	return path
+}
+
+// check 检查接口路径是否合法，路径不能包含查询参数，API版本的查询参数请使用 APIVersion.Query
+func (p HTTPPaths) check() error {
+	for _, path := range []string{p.All, p.Speech, p.Language} {
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("error: http path `%s` must start with `/`", path)
+		}
+		if strings.ContainsAny(path, "?#") {
+			return fmt.Errorf("error: http path `%s` can not contain `?` or `#`", path)
+		}
+	}
+
+	return nil
+}
+
+// WithHTTPPaths 设置HTTP协议各接口的路径，用于网关改写了接口路由的部署方式
+func WithHTTPPaths(paths HTTPPaths) Option {
+	return func(o *options) {
+		o.httpPaths = paths
+	}
+}
+
+// APIVersion HTTP协议请求携带的API版本
+type APIVersion struct {
+	// Version API版本号，为空时不携带版本
+	Version string `json:"version" yaml:"version"`
+	// Header 携带版本号的请求头，Header 和 Query 都为空时使用 X-ASRT-API-Version 请求头
+	Header string `json:"header" yaml:"header"`
+	// Query 携带版本号的URL查询参数
+	Query string `json:"query" yaml:"query"`
+}
+
+// WithAPIVersion 在每次HTTP请求中携带API版本，以便服务端或网关按版本路由，
+// 并替换接口路径中的 {version} 占位符，参见 HTTPPaths
+func WithAPIVersion(version APIVersion) Option {
+	return func(o *options) {
+		o.apiVersion = version
+	}
+}
+
+// apply 为请求添加API版本请求头
+func (v APIVersion) apply(header http.Header) {
+	if v.Version == "" {
+		return
+	}
+
+	if v.Header != "" {
+		header.Set(v.Header, v.Version)
+	} else if v.Query == "" {
+		header.Set(defaultAPIVersionHeader, v.Version)
+	}
+}
+
+// checkPaths 检查接口路径中的 {version} 占位符是否有对应的版本号，避免拼接出 "//" 这样的路径
+func (v APIVersion) checkPaths(paths HTTPPaths) error {
+	if v.Version != "" {
+		return nil
+	}
+	for _, path := range []string{paths.All, paths.Speech, paths.Language} {
+		if strings.Contains(path, apiVersionPlaceholder) {
+			return fmt.Errorf("error: http path `%s` contains %s but api version is empty", path,
+				apiVersionPlaceholder)
+		}
+	}
+
+	return nil
+}
+
+// requestURL 拼接接口的完整URL，替换路径中的版本占位符并添加版本查询参数
+func (v APIVersion) requestURL(baseURL string, path string) string {
+	path = strings.ReplaceAll(path, apiVersionPlaceholder, url.PathEscape(v.Version))
+	if v.Version == "" || v.Query == "" {
+		return baseURL + path
+	}
+
+	return baseURL + path + "?" + url.Values{v.Query: []string{v.Version}}.Encode()
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUnitAPIVersion(t *testing.T) {
+	suite.Run(t, new(TestUnitAPIVersionSuite))
+}
+
+type TestUnitAPIVersionSuite struct {
+	suite.Suite
+	mutex    sync.Mutex
+	requests []*http.Request
+}
+
+// start 启动记录请求的测试用HTTP服务端，返回端口
+func (t *TestUnitAPIVersionSuite) start() string {
+	t.requests = nil
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		t.mutex.Lock()
+		t.requests = append(t.requests, r)
+		t.mutex.Unlock()
+		fakeHTTPHandler(w, r)
+	})
+
+	return port
+}
+
+// last 获取最近一次收到的请求
+func (t *TestUnitAPIVersionSuite) last() *http.Request {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.requests[len(t.requests)-1]
+}
+
+func (t *TestUnitAPIVersionSuite) TestDefaultPaths() {
+	port := t.start()
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "/asrt")
+
+	_, err := recognizer.Recognite(make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal("/asrt/all", t.last().URL.Path)
+	_, err = recognizer.RecogniteSpeech(make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal("/asrt/speech", t.last().URL.Path)
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("/asrt/language", t.last().URL.Path)
+	t.Equal("", t.last().Header.Get(defaultAPIVersionHeader))
+}
+
+func (t *TestUnitAPIVersionSuite) TestCustomPathsAndVersion() {
+	port := t.start()
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "/gw",
+		WithHTTPPaths(HTTPPaths{All: "/v{version}/recognize", Language: "/v{version}/lm"}),
+		WithAPIVersion(APIVersion{Version: "2"}))
+
+	_, err := recognizer.Recognite(make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal("/gw/v2/recognize", t.last().URL.Path)
+	t.Equal("2", t.last().Header.Get(defaultAPIVersionHeader))
+	_, err = recognizer.RecogniteSpeech(make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal("/gw/speech", t.last().URL.Path)
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("/gw/v2/lm", t.last().URL.Path)
+}
+
+func (t *TestUnitAPIVersionSuite) TestVersionHeaderAndQuery() {
+	port := t.start()
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithAPIVersion(APIVersion{Version: "2024-01", Query: "api-version"}))
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("2024-01", t.last().URL.Query().Get("api-version"))
+	t.Equal("", t.last().Header.Get(defaultAPIVersionHeader))
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithAPIVersion(APIVersion{Version: "3", Header: "Accept-Version", Query: "v"}))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("3", t.last().Header.Get("Accept-Version"))
+	t.Equal("3", t.last().URL.Query().Get("v"))
+}
+
+func (t *TestUnitAPIVersionSuite) TestEndpointURL() {
+	port := t.start()
+	recognizer, err := NewSpeechRecognizerFromURL("http://127.0.0.1:" + port + "/gw?path_language=" +
+		url.QueryEscape("/lm") + "&api_version=2&api_version_header=X-Version")
+	t.NoError(err)
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("/gw/lm", t.last().URL.Path)
+	t.Equal("2", t.last().Header.Get("X-Version"))
+
+	_, err = NewSpeechRecognizerFromURL("http://127.0.0.1:" + port + "?path_all=all")
+	t.Error(err)
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithHTTPPaths(HTTPPaths{Speech: "speech"})))
+}
+
+func (t *TestUnitAPIVersionSuite) TestInvalidPaths() {
+	port := t.start()
+
+	// 路径中不能携带查询参数，否则与版本查询参数拼接后会出现两个 ?
+	for _, path := range []string{"/all?lang=zh", "/all#top"} {
+		t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithHTTPPaths(HTTPPaths{All: path})), path)
+		_, err := NewSpeechRecognizerFromURL("http://127.0.0.1:" + port + "?path_all=" + url.QueryEscape(path))
+		t.Error(err, path)
+		t.Error((&Config{Host: "127.0.0.1", Paths: HTTPPaths{All: path}}).Validate(), path)
+	}
+
+	// 路径包含 {version} 时必须设置版本号
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithHTTPPaths(HTTPPaths{All: "/v{version}/recognize"})))
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithHTTPPaths(HTTPPaths{Language: "/{version}/lm"}), WithAPIVersion(APIVersion{Header: "X-Version"})))
+	_, err := NewSpeechRecognizerFromURL("http://127.0.0.1:" + port + "?path_all=" + url.QueryEscape("/{version}/all"))
+	t.Error(err)
+
+	// 路径中的版本与版本查询参数同时使用时只有一个 ?
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithHTTPPaths(HTTPPaths{Language: "/{version}/lm"}), WithAPIVersion(APIVersion{Version: "2", Query: "v"}))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("/2/lm", t.last().URL.Path)
+	t.Equal("v=2", t.last().URL.RawQuery)
+}
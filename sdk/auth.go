@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// Token 访问令牌
+type Token struct {
+	// Value 令牌内容
+	Value string
+	// Type 令牌类型，作为Authorization头的认证方案，默认 "Bearer"
+	Type string
+	// Expiry 令牌过期时间，零值表示永不过期
+	Expiry time.Time
+}
+
+// valid 判断令牌在 refreshBefore 时间之后是否仍然有效
+func (t *Token) valid(refreshBefore time.Duration) bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Now().Add(refreshBefore).Before(t.Expiry))
+}
+
+// authorization 获取Authorization头的内容
+func (t *Token) authorization() string {
+	tokenType := t.Type
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+
+	return tokenType + " " + t.Value
+}
+
+// TokenSource 访问令牌的来源，每次请求前都会调用，实现需要支持并发调用
+type TokenSource interface {
+	// Token 获取当前有效的访问令牌
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc 将一个函数适配为 TokenSource 接口
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token 实现 TokenSource 接口
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource 获取总是返回同一个令牌的令牌来源
+func StaticTokenSource(token *Token) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return token, nil
+	})
+}
+
+// reuseTokenSource 缓存令牌直到临近过期的令牌来源
+type reuseTokenSource struct {
+	source        TokenSource
+	refreshBefore time.Duration
+
+	mutex sync.Mutex
+	token *Token
+}
+
+// ReuseTokenSource 包装 source，缓存其返回的令牌，并在令牌过期前 refreshBefore 时间自动重新获取，
+// 适用于每次获取令牌都需要访问认证服务的场景
+func ReuseTokenSource(source TokenSource, refreshBefore time.Duration) TokenSource {
+	return &reuseTokenSource{source: source, refreshBefore: refreshBefore}
+}
+
+// Token 实现 TokenSource 接口
+func (s *reuseTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.valid(s.refreshBefore) {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+
+	return token, nil
+}
+
+// WithBearerToken 在每次请求中以 "Authorization: Bearer <token>" 的形式携带固定的访问令牌
+func WithBearerToken(token string) Option {
+	return WithTokenSource(StaticTokenSource(&Token{Value: token}))
+}
+
+// WithTokenSource 在每次请求前从 source 获取访问令牌，并以Authorization头的形式携带，
+// gRPC协议通过 PerRPCCredentials 以 authorization 元数据的形式携带
+func WithTokenSource(source TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = source
+	}
+}
+
+// WithAPIKey 在每次请求中以自定义请求头携带API Key，例如 WithAPIKey("X-API-Key", key)，
+// gRPC协议使用小写的请求头名称作为元数据键
+func WithAPIKey(header string, key string) Option {
+	return func(o *options) {
+		headers := make(map[string]string, len(o.authHeaders)+1)
+		for k, v := range o.authHeaders {
+			headers[k] = v
+		}
+		headers[header] = key
+		o.authHeaders = headers
+	}
+}
+
+// WithInsecureAuth 允许在未使用TLS的http和grpc协议下携带认证信息，仅用于由网关终结TLS、
+// 客户端到网关之间的链路可信的部署方式，默认不允许，以免访问令牌和API Key以明文传输
+func WithInsecureAuth() Option {
+	return func(o *options) {
+		o.insecureAuth = true
+	}
+}
+
+// hasAuth 判断是否配置了认证信息
+func (o options) hasAuth() bool {
+	return o.tokenSource != nil || len(o.authHeaders) > 0
+}
+
+// authMetadata 获取每次请求需要携带的认证请求头
+func (o options) authMetadata(ctx context.Context) (map[string]string, error) {
+	if !o.hasAuth() {
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(o.authHeaders)+1)
+	for k, v := range o.authHeaders {
+		headers[k] = v
+	}
+	if o.tokenSource != nil {
+		token, err := o.tokenSource.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error: get auth token failed, %s: %w", err, common.ErrAuth)
+		}
+		if token == nil || token.Value == "" {
+			return nil, fmt.Errorf("error: empty auth token: %w", common.ErrAuth)
+		}
+		headers["Authorization"] = token.authorization()
+	}
+
+	return headers, nil
+}
+
+// perRPCAuth 为gRPC请求携带认证信息的 credentials.PerRPCCredentials 实现
+type perRPCAuth struct {
+	options       options
+	allowInsecure bool
+}
+
+// GetRequestMetadata 实现 credentials.PerRPCCredentials 接口
+func (a perRPCAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	headers, err := a.options.authMetadata(ctx)
+	if err != nil {
+		return nil, &authError{err: err}
+	}
+
+	metadata := make(map[string]string, len(headers))
+	for k, v := range headers {
+		metadata[strings.ToLower(k)] = v
+	}
+
+	return metadata, nil
+}
+
+// RequireTransportSecurity 实现 credentials.PerRPCCredentials 接口，
+// 只有通过 WithInsecureAuth 显式允许或使用Unix域套接字时才可以在grpc协议下携带认证信息
+func (a perRPCAuth) RequireTransportSecurity() bool {
+	return !a.allowInsecure
+}
+
+// authError gRPC请求获取认证信息失败的错误，
+// 携带 Unauthenticated 状态码以便gRPC原样返回，同时可以通过 errors.Is 判断为 common.ErrAuth
+type authError struct {
+	err error
+}
+
+// Error 实现 error 接口
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 获取获取认证信息失败的原始错误
+func (e *authError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus 获取对应的gRPC状态
+func (e *authError) GRPCStatus() *status.Status {
+	return status.New(codes.Unauthenticated, e.err.Error())
+}
@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitAuth(t *testing.T) {
+	suite.Run(t, new(TestUnitAuthSuite))
+}
+
+type TestUnitAuthSuite struct {
+	suite.Suite
+}
+
+// requireHeader 返回只有请求头 key 的值为 value 时才正常响应的测试用HTTP接口
+func requireHeader(key string, value string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(key) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fakeHTTPHandler(w, r)
+	}
+}
+
+func (t *TestUnitAuthSuite) TestHTTPBearerToken() {
+	_, port := startFakeHTTPServer(t.T(), requireHeader("Authorization", "Bearer secret"))
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+	_, err := recognizer.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrClient)
+
+	// 默认不允许在未使用TLS的http协议下携带认证信息
+	_, err = NewSpeechRecognizerFromURL("http://127.0.0.1:"+port, WithBearerToken("secret"))
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithBearerToken("secret")))
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithInsecureAuth(), WithBearerToken("secret"))
+	_, err = recognizer.RecogniteLanguage(nil)
+	t.NoError(err)
+
+	// 构造后将协议修改为http时，请求前同样拒绝携带认证信息
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "", WithBearerToken("secret"))
+	recognizer.Protocol = "http"
+	_, err = recognizer.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+}
+
+func (t *TestUnitAuthSuite) TestHTTPAPIKey() {
+	_, port := startFakeHTTPServer(t.T(), requireHeader("X-API-Key", "key"))
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithInsecureAuth(),
+		WithAPIKey("X-API-Key", "key"))
+	_, err := recognizer.RecogniteLanguage(nil)
+	t.NoError(err)
+}
+
+func (t *TestUnitAuthSuite) TestTokenSourceError() {
+	_, port := startFakeHTTPServer(t.T(), nil)
+	source := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return nil, errors.New("auth server down")
+	})
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithInsecureAuth(), WithTokenSource(source))
+	_, err := recognizer.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrAuth)
+}
+
+func (t *TestUnitAuthSuite) TestGRPCPerRPCCredentials() {
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if len(md.Get("authorization")) == 0 || md.Get("authorization")[0] != "Token secret" ||
+			len(md.Get("x-api-key")) == 0 || md.Get("x-api-key")[0] != "key" {
+			return nil, status.Error(codes.Unauthenticated, "bad credentials")
+		}
+		return handler(ctx, req)
+	}
+	port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+	_, err := recognizer.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrClient)
+
+	// 默认不允许在未使用TLS的grpc协议下携带认证信息
+	_, err = NewSpeechRecognizerFromURL("grpc://127.0.0.1:"+port, WithBearerToken("secret"))
+	t.Error(err)
+	t.Nil(NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithAPIKey("X-API-Key", "key")))
+
+	authed := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithInsecureAuth(),
+		WithTokenSource(StaticTokenSource(&Token{Value: "secret", Type: "Token"})),
+		WithAPIKey("X-API-Key", "key"))
+	defer authed.Close()
+	rsp, err := authed.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+}
+
+func (t *TestUnitAuthSuite) TestGRPCTokenSourceError() {
+	port := startFakeGRPCServer(t.T(), nil)
+	retried := 0
+	policy := DefaultRetryPolicy()
+	policy.OnRetry = func(event RetryEvent) { retried += 1 }
+
+	failing := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithInsecureAuth(), WithTokenSource(
+		TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+			return nil, errors.New("auth server down")
+		})), WithRetryPolicy(policy))
+	defer failing.Close()
+	_, err := failing.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrAuth)
+	t.NotErrorIs(err, common.ErrClient)
+	t.NotErrorIs(err, common.ErrTransport)
+	t.Contains(err.Error(), "auth server down")
+	t.Equal(0, retried)
+}
+
+func (t *TestUnitAuthSuite) TestReuseTokenSource() {
+	var fetches int32
+	source := ReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		return &Token{Value: "token", Expiry: time.Now().Add(time.Minute)}, nil
+	}), 10*time.Second)
+
+	for i := 0; i < 3; i += 1 {
+		token, err := source.Token(context.Background())
+		t.NoError(err)
+		t.Equal("token", token.Value)
+	}
+	t.Equal(int32(1), atomic.LoadInt32(&fetches))
+
+	// 令牌即将在 refreshBefore 内过期时重新获取
+	refreshing := ReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		return &Token{Value: "token", Expiry: time.Now().Add(5 * time.Second)}, nil
+	}), 10*time.Second)
+	_, _ = refreshing.Token(context.Background())
+	_, _ = refreshing.Token(context.Background())
+	t.Equal(int32(3), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,309 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// defaultEjectAfter 默认连续失败多少次后摘除后端
+	defaultEjectAfter = 5
+	// defaultEjectDuration 后端被摘除的默认时长
+	defaultEjectDuration = 30 * time.Second
+)
+
+// BalanceStrategy 负载均衡策略
+type BalanceStrategy int
+
+const (
+	// BalanceRoundRobin 轮询
+	BalanceRoundRobin BalanceStrategy = iota
+	// BalanceLeastOutstanding 选择正在进行的请求数最少的后端
+	BalanceLeastOutstanding
+	// BalanceWeighted 按 Backend.Weight 平滑加权轮询
+	BalanceWeighted
+)
+
+// String 获取负载均衡策略名称
+func (s BalanceStrategy) String() string {
+	switch s {
+	case BalanceRoundRobin:
+		return "round-robin"
+	case BalanceLeastOutstanding:
+		return "least-outstanding"
+	case BalanceWeighted:
+		return "weighted"
+	}
+
+	return fmt.Sprintf("strategy(%d)", int(s))
+}
+
+// Backend 负载均衡的一个后端
+type Backend struct {
+	// Name 后端名称，用于统计信息，为空时使用 "backend-序号"
+	Name string
+	// Recognizer 后端的语音识别实例，可以是任意协议的实例或其他包装类
+	Recognizer ISpeechRecognizer
+	// Weight 加权轮询时的权重，默认1
+	Weight int
+}
+
+// BalancerConfig 负载均衡配置，零值表示使用默认值
+type BalancerConfig struct {
+	// Strategy 负载均衡策略，默认轮询
+	Strategy BalanceStrategy
+	// EjectAfter 后端连续失败该次数后被暂时摘除，默认5
+	EjectAfter int
+	// EjectDuration 后端被摘除的时长，到期后重新参与负载均衡，默认30s
+	EjectDuration time.Duration
+	// IsFailure 判断一次请求的错误是否计为后端失败，默认只有传输错误和服务端错误计为失败
+	IsFailure func(err error) bool
+}
+
+// BackendStats 单个后端的统计信息
+type BackendStats struct {
+	// Name 后端名称
+	Name string
+	// Requests 已发送的请求数
+	Requests uint64
+	// Failures 计为失败的请求数
+	Failures uint64
+	// Outstanding 正在进行的请求数
+	Outstanding int
+	// ConsecutiveFailures 当前连续失败次数
+	ConsecutiveFailures int
+	// Ejected 当前是否被摘除
+	Ejected bool
+	// EjectedUntil 被摘除的截止时间，未被摘除时为零值
+	EjectedUntil time.Time
+	// AverageLatency 已完成请求的平均耗时
+	AverageLatency time.Duration
+}
+
+// balancerBackend 负载均衡器内部的后端状态
+type balancerBackend struct {
+	Backend
+
+	recognizer ContextSpeechRecognizer
+
+	currentWeight       int
+	requests            uint64
+	failures            uint64
+	completed           uint64
+	totalLatency        time.Duration
+	outstanding         int
+	consecutiveFailures int
+	ejectedUntil        time.Time
+}
+
+// Balancer 在多个ASRT服务端之间做负载均衡的语音识别实例
+//
+// 每次请求按策略选择一个后端，连续失败的后端会被暂时摘除；所有后端都被摘除时不再跳过任何后端。
+// 长音频识别的每个片段会分别选择后端
+type Balancer struct {
+	recognizerMixin
+
+	config BalancerConfig
+	now    func() time.Time
+
+	mutex    sync.Mutex
+	backends []*balancerBackend
+	next     int
+}
+
+// NewBalancer 构造一个在 backends 之间做负载均衡的语音识别实例
+func NewBalancer(backends []Backend, config BalancerConfig) (*Balancer, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("error: no backend for balancer")
+	}
+	if config.EjectAfter <= 0 {
+		config.EjectAfter = defaultEjectAfter
+	}
+	if config.EjectDuration <= 0 {
+		config.EjectDuration = defaultEjectDuration
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isBreakerFailure
+	}
+
+	balancer := &Balancer{
+		config: config,
+		now:    time.Now,
+	}
+	for i, backend := range backends {
+		if backend.Recognizer == nil {
+			return nil, fmt.Errorf("error: backend %d has no recognizer", i)
+		}
+		if backend.Name == "" {
+			backend.Name = fmt.Sprintf("backend-%d", i)
+		}
+		if backend.Weight <= 0 {
+			backend.Weight = 1
+		}
+		balancer.backends = append(balancer.backends, &balancerBackend{
+			Backend:    backend,
+			recognizer: withContext(backend.Recognizer),
+		})
+	}
+	balancer.recognizerMixin = recognizerMixin{self: balancer}
+
+	return balancer, nil
+}
+
+// Stats 获取各个后端的统计信息，顺序与构造时传入的顺序一致
+func (b *Balancer) Stats() []BackendStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	stats := make([]BackendStats, 0, len(b.backends))
+	for _, backend := range b.backends {
+		stat := BackendStats{
+			Name:                backend.Name,
+			Requests:            backend.requests,
+			Failures:            backend.failures,
+			Outstanding:         backend.outstanding,
+			ConsecutiveFailures: backend.consecutiveFailures,
+		}
+		if now.Before(backend.ejectedUntil) {
+			stat.Ejected = true
+			stat.EjectedUntil = backend.ejectedUntil
+		}
+		if backend.completed > 0 {
+			stat.AverageLatency = backend.totalLatency / time.Duration(backend.completed)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats
+}
+
+// RecogniteContext 选择一个后端调用ASRT语音识别
+func (b *Balancer) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteSpeechContext 选择一个后端调用ASRT语音识别声学模型
+func (b *Balancer) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteLanguageContext 选择一个后端调用ASRT语音识别语言模型
+func (b *Balancer) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteLanguageContext(ctx, sequencePinyin)
+	})
+}
+
+// call 选择一个后端调用 fn，并记录调用结果
+func (b *Balancer) call(ctx context.Context,
+	fn func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	backend := b.pick()
+	start := b.now()
+	rsp, err := fn(ctx, backend.recognizer)
+	b.done(backend, b.now().Sub(start), err)
+
+	return rsp, err
+}
+
+// pick 按策略选择一个后端，并将其正在进行的请求数加一
+func (b *Balancer) pick() *balancerBackend {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	available := make([]bool, len(b.backends))
+	anyAvailable := false
+	for i, backend := range b.backends {
+		available[i] = !now.Before(backend.ejectedUntil)
+		anyAvailable = anyAvailable || available[i]
+	}
+	if !anyAvailable {
+		for i := range available {
+			available[i] = true
+		}
+	}
+
+	var chosen int
+	switch b.config.Strategy {
+	case BalanceLeastOutstanding:
+		chosen = -1
+		for k := range b.backends {
+			i := (b.next + k) % len(b.backends)
+			if available[i] && (chosen < 0 || b.backends[i].outstanding < b.backends[chosen].outstanding) {
+				chosen = i
+			}
+		}
+		b.next = (chosen + 1) % len(b.backends)
+	case BalanceWeighted:
+		chosen = -1
+		totalWeight := 0
+		for i, backend := range b.backends {
+			if !available[i] {
+				continue
+			}
+			backend.currentWeight += backend.Weight
+			totalWeight += backend.Weight
+			if chosen < 0 || backend.currentWeight > b.backends[chosen].currentWeight {
+				chosen = i
+			}
+		}
+		b.backends[chosen].currentWeight -= totalWeight
+	default:
+		for k := range b.backends {
+			i := (b.next + k) % len(b.backends)
+			if available[i] {
+				chosen = i
+				break
+			}
+		}
+		b.next = (chosen + 1) % len(b.backends)
+	}
+
+	backend := b.backends[chosen]
+	backend.outstanding += 1
+	backend.requests += 1
+
+	return backend
+}
+
+// done 记录一次请求的结果，连续失败达到阈值时摘除后端
+func (b *Balancer) done(backend *balancerBackend, latency time.Duration, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	backend.outstanding -= 1
+	backend.completed += 1
+	backend.totalLatency += latency
+
+	// 调用方主动取消的请求不能说明后端是否可用
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err == nil || !b.config.IsFailure(err) {
+		backend.consecutiveFailures = 0
+		return
+	}
+
+	backend.failures += 1
+	backend.consecutiveFailures += 1
+	if backend.consecutiveFailures >= b.config.EjectAfter {
+		backend.ejectedUntil = b.now().Add(b.config.EjectDuration)
+		backend.consecutiveFailures = 0
+	}
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitBalancer(t *testing.T) {
+	suite.Run(t, new(TestUnitBalancerSuite))
+}
+
+type TestUnitBalancerSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitBalancerSuite) TestRoundRobin() {
+	fakes := []*fakeRecognizer{okRecognizer(), okRecognizer(), okRecognizer()}
+	balancer, err := NewBalancer([]Backend{
+		{Recognizer: fakes[0]}, {Recognizer: fakes[1]}, {Recognizer: fakes[2]},
+	}, BalancerConfig{})
+	t.NoError(err)
+
+	for i := 0; i < 9; i += 1 {
+		_, err := balancer.RecogniteLanguage(nil)
+		t.NoError(err)
+	}
+	for _, fake := range fakes {
+		t.Equal(3, fake.calls)
+	}
+
+	stats := balancer.Stats()
+	t.Equal("backend-1", stats[1].Name)
+	t.Equal(uint64(3), stats[1].Requests)
+	t.Equal(0, stats[1].Outstanding)
+}
+
+func (t *TestUnitBalancerSuite) TestWeighted() {
+	heavy, light := okRecognizer(), okRecognizer()
+	balancer, err := NewBalancer([]Backend{
+		{Name: "heavy", Recognizer: heavy, Weight: 3},
+		{Name: "light", Recognizer: light, Weight: 1},
+	}, BalancerConfig{Strategy: BalanceWeighted})
+	t.NoError(err)
+
+	for i := 0; i < 8; i += 1 {
+		_, _ = balancer.RecogniteLanguage(nil)
+	}
+	t.Equal(6, heavy.calls)
+	t.Equal(2, light.calls)
+}
+
+func (t *TestUnitBalancerSuite) TestLeastOutstanding() {
+	balancer, err := NewBalancer([]Backend{
+		{Recognizer: okRecognizer()}, {Recognizer: okRecognizer()}, {Recognizer: okRecognizer()},
+	}, BalancerConfig{Strategy: BalanceLeastOutstanding})
+	t.NoError(err)
+
+	first := balancer.pick()
+	second := balancer.pick()
+	third := balancer.pick()
+	t.NotEqual(first.Name, second.Name)
+	t.NotEqual(second.Name, third.Name)
+	t.NotEqual(first.Name, third.Name)
+
+	balancer.done(second, 0, nil)
+	t.Equal(second.Name, balancer.pick().Name)
+}
+
+func (t *TestUnitBalancerSuite) TestPassiveEjection() {
+	broken := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, &common.TransportError{Op: "language", Err: errors.New("connection refused")}
+	})
+	healthy := okRecognizer()
+	balancer, err := NewBalancer([]Backend{
+		{Name: "broken", Recognizer: broken},
+		{Name: "healthy", Recognizer: healthy},
+	}, BalancerConfig{EjectAfter: 2, EjectDuration: time.Minute})
+	t.NoError(err)
+	now := time.Unix(1000, 0)
+	balancer.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i += 1 {
+		_, _ = balancer.RecogniteLanguage(nil)
+	}
+	t.Equal(2, broken.calls)
+	stats := balancer.Stats()
+	t.True(stats[0].Ejected)
+	t.Equal(uint64(2), stats[0].Failures)
+	t.False(stats[1].Ejected)
+
+	for i := 0; i < 4; i += 1 {
+		_, err := balancer.RecogniteLanguage(nil)
+		t.NoError(err)
+	}
+	t.Equal(2, broken.calls)
+
+	// 摘除到期后重新参与负载均衡
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i += 1 {
+		_, _ = balancer.RecogniteLanguage(nil)
+	}
+	t.Equal(3, broken.calls)
+}
+
+func (t *TestUnitBalancerSuite) TestInvalidBackends() {
+	_, err := NewBalancer(nil, BalancerConfig{})
+	t.Error(err)
+	_, err = NewBalancer([]Backend{{Name: "empty"}}, BalancerConfig{})
+	t.Error(err)
+}
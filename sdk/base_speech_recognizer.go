@@ -1,9 +1,15 @@
 package sdk
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/nl8590687/asrt-sdk-go/common"
 )
 
+var wavDataMaxLength = 16000 * 2 * 16
+
 // ISpeechRecognizer ASRT语音识别SDK语音识别抽象接口
 type ISpeechRecognizer interface {
 	// Recognite 调用ASRT语音识别
@@ -12,12 +18,33 @@ type ISpeechRecognizer interface {
 	RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int) (*common.AsrtAPIResponse, error)
 	// RecogniteLanguage 调用ASRT语音识别语言模型
 	RecogniteLanguage(sequencePinyin []string) (*common.AsrtAPIResponse, error)
-	// RecogniteLong
+	// RecogniteLong 调用ASRT语音识别来识别长音频序列
 	RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int) ([]*common.AsrtAPIResponse, error)
 	// RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
 	RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error)
 }
 
+// ContextSpeechRecognizer 支持通过 context 控制超时和取消的语音识别接口，
+// SDK 提供的所有语音识别调用类都实现了该接口，长音频识别的可选配置项也只能通过该接口的方法传入
+type ContextSpeechRecognizer interface {
+	ISpeechRecognizer
+
+	// RecogniteContext 调用ASRT语音识别，可以通过 ctx 控制超时和取消
+	RecogniteContext(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+	) (*common.AsrtAPIResponse, error)
+	// RecogniteSpeechContext 调用ASRT语音识别声学模型，可以通过 ctx 控制超时和取消
+	RecogniteSpeechContext(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+	) (*common.AsrtAPIResponse, error)
+	// RecogniteLanguageContext 调用ASRT语音识别语言模型，可以通过 ctx 控制超时和取消
+	RecogniteLanguageContext(ctx context.Context, sequencePinyin []string) (*common.AsrtAPIResponse, error)
+	// RecogniteLongContext 调用ASRT语音识别来识别长音频序列，可以通过 ctx 控制超时和取消
+	RecogniteLongContext(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+		opts ...RecogniteLongOption) ([]*common.AsrtAPIResponse, error)
+	// RecogniteFileContext 调用ASRT语音识别来识别指定文件名的音频文件，可以通过 ctx 控制超时和取消
+	RecogniteFileContext(ctx context.Context, filename string,
+		opts ...RecogniteLongOption) ([]*common.AsrtAPIResponse, error)
+}
+
 // BaseSpeechRecognizer ASRT语音识别SDK语音识别基类
 type BaseSpeechRecognizer struct {
 	// Host 主机域名或IP
@@ -26,4 +53,62 @@ type BaseSpeechRecognizer struct {
 	Port string
 	// Protocol 网络协议
 	Protocol string
+
+	options options
+}
+
+// logger 获取日志实现，未通过构造函数创建的实例不输出日志
+func (b *BaseSpeechRecognizer) logger() common.Logger {
+	if b.options.logger == nil {
+		return common.NopLogger{}
+	}
+
+	return b.options.logger
+}
+
+// invoke 调用一次识别操作，配置了超时时间时为每次尝试设置超时，配置了重试策略时按策略自动重试，
+// 所有尝试使用同一个请求ID，并在返回的响应对象或错误中记录该请求ID
+func (b *BaseSpeechRecognizer) invoke(ctx context.Context, op string,
+	call func(ctx context.Context) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	if timeout := b.options.timeout; timeout > 0 {
+		attempt := call
+		call = func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return attempt(ctx)
+		}
+	}
+	ctx, requestID := ensureRequestID(ctx)
+	var response *common.AsrtAPIResponse
+	var err error
+	if b.options.retry == nil || ctx.Value(noRetryKey{}) != nil {
+		response, err = call(ctx)
+	} else {
+		response, err = b.options.retry.do(ctx, op, b.logger(), call)
+	}
+	tagRequestID(response, err, requestID)
+
+	return response, err
+}
+
+// checkWavDataLength 检查单次识别的音频数据长度是否超过上限
+func checkWavDataLength(wavData []byte) error {
+	if len(wavData) > wavDataMaxLength {
+		return fmt.Errorf("error: %s `%d`, %s `%d`: %w",
+			"Too long wave sample byte length:", len(wavData),
+			"the max length is", wavDataMaxLength, common.ErrClientFormat)
+	}
+
+	return nil
+}
+
+// checkAPIResponse 检查ASRT接口响应状态，部分识别结果同时返回响应对象和错误，其他非成功状态只返回错误
+func checkAPIResponse(response *common.AsrtAPIResponse) (*common.AsrtAPIResponse, error) {
+	err := common.CheckAPIResponse(response)
+	if err != nil && !errors.Is(err, common.ErrPartialResult) {
+		return nil, err
+	}
+
+	return response, err
 }
@@ -0,0 +1,316 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// defaultBreakerWindow 熔断器统计失败率的默认时间窗口
+	defaultBreakerWindow = 10 * time.Second
+	// defaultBreakerMinRequests 时间窗口内触发熔断所需的默认最少请求数
+	defaultBreakerMinRequests = 10
+	// defaultBreakerFailureRate 触发熔断的默认失败率
+	defaultBreakerFailureRate = 0.5
+	// defaultBreakerOpenTimeout 熔断器打开后进入半开状态前的默认等待时间
+	defaultBreakerOpenTimeout = 30 * time.Second
+	// breakerBuckets 时间窗口划分的桶数
+	breakerBuckets = 10
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// CircuitClosed 关闭状态，请求正常发送并统计失败率
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 打开状态，请求直接以 common.ErrCircuitOpen 失败
+	CircuitOpen
+	// CircuitHalfOpen 半开状态，只放行少量试探请求，全部成功后关闭，任一失败则重新打开
+	CircuitHalfOpen
+)
+
+// String 获取熔断器状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+// CircuitBreakerConfig 熔断器配置，零值表示使用默认值
+type CircuitBreakerConfig struct {
+	// Window 统计失败率的滑动时间窗口，默认10s
+	Window time.Duration
+	// MinRequests 时间窗口内的请求数达到该值后才会根据失败率触发熔断，默认10
+	MinRequests int
+	// FailureRate 触发熔断的失败率，取值范围 (0, 1]，默认0.5
+	FailureRate float64
+	// OpenTimeout 熔断器打开后，经过该时间进入半开状态，默认30s
+	OpenTimeout time.Duration
+	// HalfOpenRequests 半开状态下允许同时进行的试探请求数，这些请求全部成功后熔断器关闭，默认1
+	HalfOpenRequests int
+	// IsFailure 判断一次请求的错误是否计为失败，默认只有传输错误和服务端错误计为失败，
+	// ctx 被取消导致的错误始终不计入统计
+	IsFailure func(err error) bool
+	// OnStateChange 熔断器状态变化时的回调，在熔断器内部锁之外调用，为nil时不回调
+	OnStateChange func(from CircuitState, to CircuitState)
+}
+
+// circuitBucket 时间窗口中一个桶的请求统计
+type circuitBucket struct {
+	start     int64
+	successes int
+	failures  int
+}
+
+// CircuitBreaker 包装任意 ISpeechRecognizer 的熔断器
+//
+// 时间窗口内的失败率超过阈值时熔断器打开，此后的请求直接以 common.ErrCircuitOpen 失败，
+// 不再访问已经不可用的服务端。长音频识别的每个片段都会分别经过熔断器
+type CircuitBreaker struct {
+	recognizerMixin
+
+	next   ContextSpeechRecognizer
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mutex      sync.Mutex
+	state      CircuitState
+	generation uint64
+	openedAt   time.Time
+	buckets    [breakerBuckets]circuitBucket
+	inFlight   int
+	successes  int
+}
+
+// NewCircuitBreaker 构造一个包装 next 的熔断器，初始为关闭状态
+func NewCircuitBreaker(next ISpeechRecognizer, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.Window <= 0 {
+		config.Window = defaultBreakerWindow
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaultBreakerMinRequests
+	}
+	if config.FailureRate <= 0 || config.FailureRate > 1 {
+		config.FailureRate = defaultBreakerFailureRate
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isBreakerFailure
+	}
+
+	breaker := &CircuitBreaker{
+		next:   withContext(next),
+		config: config,
+		now:    time.Now,
+	}
+	breaker.recognizerMixin = recognizerMixin{self: breaker}
+
+	return breaker
+}
+
+// State 获取熔断器当前状态，打开状态在超时后的下一次请求时才会转为半开状态
+func (b *CircuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// RecogniteContext 经过熔断器调用ASRT语音识别
+func (b *CircuitBreaker) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return b.next.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteSpeechContext 经过熔断器调用ASRT语音识别声学模型
+func (b *CircuitBreaker) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return b.next.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteLanguageContext 经过熔断器调用ASRT语音识别语言模型
+func (b *CircuitBreaker) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return b.call(ctx, func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return b.next.RecogniteLanguageContext(ctx, sequencePinyin)
+	})
+}
+
+// call 在熔断器允许时调用 fn，并记录调用结果
+func (b *CircuitBreaker) call(ctx context.Context,
+	fn func(ctx context.Context) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	generation, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := fn(ctx)
+	b.record(generation, err)
+
+	return rsp, err
+}
+
+// allow 判断是否放行一次请求，放行时返回当前状态的代数，用于忽略状态变化前发出的请求结果
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mutex.Lock()
+	var notify func()
+	defer func() {
+		b.mutex.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+
+	if b.state == CircuitOpen {
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return 0, common.ErrCircuitOpen
+		}
+		notify = b.setState(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.inFlight >= b.config.HalfOpenRequests {
+			return 0, common.ErrCircuitOpen
+		}
+		b.inFlight += 1
+	}
+
+	return b.generation, nil
+}
+
+// record 记录一次请求的结果并按需切换状态
+func (b *CircuitBreaker) record(generation uint64, err error) {
+	b.mutex.Lock()
+	var notify func()
+	defer func() {
+		b.mutex.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+
+	if generation != b.generation {
+		return
+	}
+
+	// 调用方主动取消的请求不能说明服务端是否可用，超时则计为传输错误
+	ignored := errors.Is(err, context.Canceled)
+	failed := err != nil && !ignored && b.config.IsFailure(err)
+
+	switch b.state {
+	case CircuitClosed:
+		if ignored {
+			return
+		}
+		bucket := b.currentBucket()
+		if failed {
+			bucket.failures += 1
+		} else {
+			bucket.successes += 1
+		}
+
+		successes, failures := b.windowCounts()
+		total := successes + failures
+		if total >= b.config.MinRequests && float64(failures) >= float64(total)*b.config.FailureRate {
+			notify = b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		b.inFlight -= 1
+		if ignored {
+			return
+		}
+		if failed {
+			notify = b.setState(CircuitOpen)
+			return
+		}
+		b.successes += 1
+		if b.successes >= b.config.HalfOpenRequests {
+			notify = b.setState(CircuitClosed)
+		}
+	}
+}
+
+// setState 切换状态并重置统计，返回需要在锁外调用的状态变化回调
+func (b *CircuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	b.state = state
+	b.generation += 1
+	b.inFlight = 0
+	b.successes = 0
+	b.buckets = [breakerBuckets]circuitBucket{}
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+
+	if b.config.OnStateChange == nil || from == state {
+		return nil
+	}
+
+	return func() {
+		b.config.OnStateChange(from, state)
+	}
+}
+
+// currentBucket 获取当前时间所在的桶，桶中是上一轮窗口的旧数据时先清空
+func (b *CircuitBreaker) currentBucket() *circuitBucket {
+	width := int64(b.config.Window / breakerBuckets)
+	if width <= 0 {
+		width = 1
+	}
+
+	start := b.now().UnixNano() / width
+	bucket := &b.buckets[start%breakerBuckets]
+	if bucket.start != start {
+		*bucket = circuitBucket{start: start}
+	}
+
+	return bucket
+}
+
+// windowCounts 统计时间窗口内的成功和失败请求数
+func (b *CircuitBreaker) windowCounts() (successes int, failures int) {
+	width := int64(b.config.Window / breakerBuckets)
+	if width <= 0 {
+		width = 1
+	}
+
+	current := b.now().UnixNano() / width
+	for _, bucket := range b.buckets {
+		if current-bucket.start < breakerBuckets {
+			successes += bucket.successes
+			failures += bucket.failures
+		}
+	}
+
+	return successes, failures
+}
+
+// isBreakerFailure 默认的失败判断，只有传输错误和服务端错误说明服务端可能不可用
+func isBreakerFailure(err error) bool {
+	return errors.Is(err, common.ErrTransport) || errors.Is(err, common.ErrServer)
+}
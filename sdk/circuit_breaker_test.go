@@ -0,0 +1,117 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitCircuitBreaker(t *testing.T) {
+	suite.Run(t, new(TestUnitCircuitBreakerSuite))
+}
+
+type TestUnitCircuitBreakerSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitCircuitBreakerSuite) TestStateTransitions() {
+	var backendErr error
+	fake := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		if backendErr != nil {
+			return nil, backendErr
+		}
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+	})
+
+	var transitions []string
+	breaker := NewCircuitBreaker(fake, CircuitBreakerConfig{
+		MinRequests: 4,
+		FailureRate: 0.5,
+		OpenTimeout: time.Second,
+		OnStateChange: func(from CircuitState, to CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	now := time.Unix(1000, 0)
+	breaker.now = func() time.Time { return now }
+
+	// 客户端错误说明服务端可用，不计为失败
+	backendErr = &common.APIError{StatusCode: common.APIStatusCodeClientErrorFormat}
+	for i := 0; i < 4; i += 1 {
+		_, err := breaker.RecogniteLanguage([]string{"ni3"})
+		t.ErrorIs(err, common.ErrClientFormat)
+	}
+	t.Equal(CircuitClosed, breaker.State())
+
+	backendErr = &common.TransportError{Op: "language", Err: errors.New("connection refused")}
+	for i := 0; i < 4; i += 1 {
+		_, _ = breaker.RecogniteLanguage([]string{"ni3"})
+	}
+	t.Equal(CircuitOpen, breaker.State())
+
+	calls := fake.calls
+	_, err := breaker.RecogniteLanguage([]string{"ni3"})
+	t.ErrorIs(err, common.ErrCircuitOpen)
+	t.Equal(calls, fake.calls)
+
+	// 半开状态下试探失败，重新打开
+	now = now.Add(time.Second)
+	_, err = breaker.RecogniteLanguage([]string{"ni3"})
+	t.ErrorIs(err, common.ErrTransport)
+	t.Equal(CircuitOpen, breaker.State())
+
+	// 半开状态下试探成功，关闭
+	now = now.Add(time.Second)
+	backendErr = nil
+	_, err = breaker.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal(CircuitClosed, breaker.State())
+
+	t.Equal([]string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func (t *TestUnitCircuitBreakerSuite) TestWindowExpiry() {
+	fake := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, &common.APIError{StatusCode: common.APIStatusCodeServerErrorRunning}
+	})
+	breaker := NewCircuitBreaker(fake, CircuitBreakerConfig{Window: 10 * time.Second, MinRequests: 3})
+	now := time.Unix(1000, 0)
+	breaker.now = func() time.Time { return now }
+
+	// 超出时间窗口的失败不再计入统计
+	_, _ = breaker.RecogniteLanguage(nil)
+	_, _ = breaker.RecogniteLanguage(nil)
+	now = now.Add(11 * time.Second)
+	_, _ = breaker.RecogniteLanguage(nil)
+	t.Equal(CircuitClosed, breaker.State())
+
+	_, _ = breaker.RecogniteLanguage(nil)
+	_, _ = breaker.RecogniteLanguage(nil)
+	t.Equal(CircuitOpen, breaker.State())
+}
+
+func (t *TestUnitCircuitBreakerSuite) TestCanceledIgnored() {
+	fake := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, ctx.Err()
+	})
+	breaker := NewCircuitBreaker(fake, CircuitBreakerConfig{MinRequests: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 5; i += 1 {
+		_, err := breaker.RecogniteLanguageContext(ctx, nil)
+		t.ErrorIs(err, context.Canceled)
+	}
+	t.Equal(CircuitClosed, breaker.State())
+}
@@ -0,0 +1,50 @@
+package sdk
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	// 注册gzip压缩器，使 grpc.UseCompressor("gzip") 可用
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+const (
+	// CompressionNone 不压缩请求
+	CompressionNone = ""
+	// CompressionGzip 使用gzip压缩请求
+	CompressionGzip = "gzip"
+)
+
+// WithCompression 压缩发送给服务端的请求，以节省慢速链路上的带宽，默认不压缩：
+//
+// HTTP协议只支持 CompressionGzip，以 "Content-Encoding: gzip" 请求头发送压缩后的请求体，
+// 需要服务端或网关支持解压；gRPC协议可以使用任意已经通过 encoding.RegisterCompressor 注册的压缩器，
+// 服务端需要注册同名的压缩器。16bit PCM音频的压缩率通常只有10%~30%，压缩会增加客户端的CPU开销
+func WithCompression(name string) Option {
+	return func(o *options) {
+		o.compression = name
+	}
+}
+
+// checkHTTPCompression 检查HTTP协议是否支持配置的压缩算法
+func (o options) checkHTTPCompression() error {
+	switch o.compression {
+	case CompressionNone, CompressionGzip:
+		return nil
+	}
+
+	return fmt.Errorf("error: unsupported http compression `%s`, must be gzip", o.compression)
+}
+
+// grpcCompressionOption 获取gRPC协议压缩请求的拨号配置项，未设置时返回nil
+func (o options) grpcCompressionOption() (grpc.DialOption, error) {
+	if o.compression == CompressionNone {
+		return nil, nil
+	}
+	if encoding.GetCompressor(o.compression) == nil {
+		return nil, fmt.Errorf("error: grpc compressor `%s` is not registered", o.compression)
+	}
+
+	return grpc.WithDefaultCallOptions(grpc.UseCompressor(o.compression)), nil
+}
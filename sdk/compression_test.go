@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// countingCompressor 记录压缩次数的测试用gRPC压缩器
+type countingCompressor struct {
+	encoding.Compressor
+	compressed int32
+}
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	atomic.AddInt32(&c.compressed, 1)
+	return c.Compressor.Compress(w)
+}
+
+func (c *countingCompressor) Name() string {
+	return "asrt-test-gzip"
+}
+
+var testCompressor = &countingCompressor{Compressor: encoding.GetCompressor(CompressionGzip)}
+
+func init() {
+	encoding.RegisterCompressor(testCompressor)
+}
+
+func TestUnitCompression(t *testing.T) {
+	suite.Run(t, new(TestUnitCompressionSuite))
+}
+
+type TestUnitCompressionSuite struct {
+	suite.Suite
+}
+
+// readBody 读取完整的请求体并检查长度
+func (t *TestUnitCompressionSuite) readBody(body httpBody) []byte {
+	reader, length := body.open(CompressionNone)
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	t.NoError(err)
+	t.Equal(length, int64(len(data)))
+	return data
+}
+
+func (t *TestUnitCompressionSuite) TestSpeechBody() {
+	for _, size := range []int{0, 1, 2, 3, 4, 32000} {
+		wavData := make([]byte, size)
+		for i := range wavData {
+			wavData[i] = byte(i * 7)
+		}
+
+		expected, err := json.Marshal(common.AsrtAPISpeechRequest{
+			Samples:    common.BytesToBase64(wavData),
+			SampleRate: 16000,
+			Channels:   1,
+			ByteWidth:  2,
+		})
+		t.NoError(err)
+		t.Equal(string(expected), string(t.readBody(speechBody(wavData, 16000, 1, 2))))
+	}
+}
+
+func (t *TestUnitCompressionSuite) TestHTTPGzip() {
+	var encodings []string
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gzipReader
+		}
+
+		request := common.AsrtAPISpeechRequest{}
+		if err := json.NewDecoder(body).Decode(&request); err != nil || request.SampleRate != 16000 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fakeHTTPHandler(w, r)
+	})
+	wave := common.GenerateSine(440, 0.5, time.Second, 16000, 1)
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithCompression(CompressionGzip))
+	rsp, err := recognizer.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+	_, err = recognizer.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+	t.Equal([]string{"gzip", ""}, encodings)
+
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithCompression("br")))
+}
+
+func (t *TestUnitCompressionSuite) TestHTTPRetryResendsBody() {
+	var requests int32
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if int64(len(data)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fakeHTTPHandler(w, r)
+	})
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithRetryPolicy(policy))
+	_, err := recognizer.Recognite(make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal(int32(2), atomic.LoadInt32(&requests))
+}
+
+func (t *TestUnitCompressionSuite) TestGRPCCompressor() {
+	port := startFakeGRPCServer(t.T(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithCompression(testCompressor.Name()))
+	defer recognizer.Close()
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Greater(atomic.LoadInt32(&testCompressor.compressed), int32(0))
+
+	gzipRecognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithCompression(CompressionGzip))
+	defer gzipRecognizer.Close()
+	_, err = gzipRecognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+
+	t.Nil(NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithCompression("zstd")))
+}
@@ -0,0 +1,568 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration 配置文件中的时长，使用 time.ParseDuration 的格式，例如 "5s"、"1m30s"
+type Duration time.Duration
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("error: invalid duration `%s`", text)
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// TLSFileConfig 配置文件中的TLS配置，证书均以文件路径的形式指定
+type TLSFileConfig struct {
+	// CAFile 校验服务端证书使用的CA证书文件
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+	// CertFile mTLS客户端证书文件
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	// KeyFile mTLS客户端私钥文件
+	KeyFile string `json:"key_file" yaml:"key_file"`
+	// ServerName 校验服务端证书使用的主机名
+	ServerName string `json:"server_name" yaml:"server_name"`
+	// MinVersion 最低TLS版本，"1.2"或"1.3"，默认1.2
+	MinVersion string `json:"min_version" yaml:"min_version"`
+	// InsecureSkipVerify 不校验服务端证书，只应在测试环境中使用
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// empty 判断是否未设置任何TLS配置
+func (c TLSFileConfig) empty() bool {
+	return c == TLSFileConfig{}
+}
+
+// PreprocessConfig 配置文件中预处理链的一个步骤
+type PreprocessConfig struct {
+	// Type 步骤类型，可以是以下取值：
+	//
+	//	mono       混合为单声道，不使用 Value
+	//	resample   重采样到 Value (Hz)，默认16000
+	//	highpass   截止频率为 Value (Hz) 的高通滤波
+	//	lowpass    截止频率为 Value (Hz) 的低通滤波
+	//	gain       施加 Value (dB) 的增益
+	//	normalize  峰值归一化到 Value (dBFS)，默认-1
+	Type string `json:"type" yaml:"type"`
+	// Value 步骤参数
+	Value float64 `json:"value" yaml:"value"`
+}
+
+// step 获取预处理步骤
+func (c PreprocessConfig) step() (PreprocessStep, error) {
+	switch c.Type {
+	case "mono":
+		return PreprocessMono(), nil
+	case "resample":
+		rate := c.Value
+		if rate == 0 {
+			rate = 16000
+		}
+		if rate < 0 || rate != float64(int(rate)) {
+			return nil, fmt.Errorf("error: invalid resample rate `%v`", c.Value)
+		}
+		return PreprocessResample(int(rate)), nil
+	case "highpass", "lowpass":
+		if c.Value <= 0 {
+			return nil, fmt.Errorf("error: invalid %s cutoff `%v`", c.Type, c.Value)
+		}
+		if c.Type == "highpass" {
+			return PreprocessHighPass(c.Value), nil
+		}
+		return PreprocessLowPass(c.Value), nil
+	case "gain":
+		return PreprocessGain(c.Value), nil
+	case "normalize":
+		peak := c.Value
+		if peak == 0 {
+			peak = -1
+		}
+		if peak > 0 {
+			return nil, fmt.Errorf("error: invalid normalize peak `%v` dBFS, must not be greater than 0", c.Value)
+		}
+		return PreprocessNormalize(peak), nil
+	}
+
+	return nil, fmt.Errorf("error: unsupported preprocess type `%s`", c.Type)
+}
+
+// Config 语音识别客户端配置，可以从JSON或YAML配置文件和 ASRT_* 环境变量加载，
+// 各来源的优先级从低到高为：
+//
+//  1. 内置默认值
+//  2. 配置文件，参见 LoadConfigFile
+//  3. ASRT_* 环境变量，参见 Config.ApplyEnv
+//  4. 调用 Config.NewSpeechRecognizer 时传入的 opts
+//  5. 每个服务端地址URL中的查询参数，参见 NewSpeechRecognizerFromURL
+//
+// YAML配置文件示例：
+//
+//	endpoints:
+//	  - grpcs://asr-1.internal:20002
+//	  - grpcs://asr-2.internal:20002
+//	balance: least-outstanding
+//	timeout: 5s
+//	retries: 3
+//	tls:
+//	  ca_file: /etc/asrt/ca.pem
+//	preprocess:
+//	  - type: mono
+//	  - type: resample
+//	    value: 16000
+//	  - type: highpass
+//	    value: 80
+type Config struct {
+	// Endpoints 服务端地址URL列表，格式参见 NewSpeechRecognizerFromURL，
+	// 多个地址时通过 Balancer 负载均衡，不能与 Host 同时设置
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	// Host 服务端主机名，与 Port、Protocol、SubPath 一起指定单个服务端
+	Host string `json:"host" yaml:"host"`
+	// Port 服务端端口，默认为 Protocol 对应的默认端口
+	Port int `json:"port" yaml:"port"`
+	// Protocol 协议，http、https、grpc或grpcs，默认http
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// SubPath HTTP协议的URL子路径
+	SubPath string `json:"sub_path" yaml:"sub_path"`
+	// Balance 多个服务端时的负载均衡策略，round-robin或least-outstanding，默认round-robin
+	Balance string `json:"balance" yaml:"balance"`
+	// Timeout 单次请求的超时时间，参见 WithTimeout
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+	// Retries 失败后的最多重试次数，大于0时使用默认重试策略
+	Retries int `json:"retries" yaml:"retries"`
+	// TLS TLS和mTLS配置
+	TLS TLSFileConfig `json:"tls" yaml:"tls"`
+	// Token Bearer访问令牌，参见 WithBearerToken
+	Token string `json:"token" yaml:"token"`
+	// APIKey API Key，参见 WithAPIKey
+	APIKey string `json:"api_key" yaml:"api_key"`
+	// APIKeyHeader API Key的请求头名称，默认 X-API-Key
+	APIKeyHeader string `json:"api_key_header" yaml:"api_key_header"`
+	// Proxy 代理服务器地址，参见 WithProxy，为nil时遵循代理环境变量，为空字符串时直接连接
+	Proxy *string `json:"proxy" yaml:"proxy"`
+	// Paths HTTP协议各接口的路径，参见 WithHTTPPaths
+	Paths HTTPPaths `json:"paths" yaml:"paths"`
+	// APIVersion HTTP协议请求携带的API版本，参见 WithAPIVersion
+	APIVersion APIVersion `json:"api_version" yaml:"api_version"`
+	// Compression 请求压缩算法，参见 WithCompression
+	Compression string `json:"compression" yaml:"compression"`
+	// Preprocess 发送请求前按顺序执行的音频预处理链，参见 Preprocessor
+	Preprocess []PreprocessConfig `json:"preprocess" yaml:"preprocess"`
+}
+
+// LoadConfigFile 从配置文件加载配置，按扩展名识别格式，支持 .json、.yaml 和 .yml，
+// 配置文件中包含未知字段时返回错误，以便发现拼写错误
+func LoadConfigFile(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error: read config file failed, %w", err)
+	}
+
+	config := &Config{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+	default:
+		return nil, fmt.Errorf("error: unsupported config file format `%s`", filepath.Ext(filename))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: parse config file `%s` failed, %w", filename, err)
+	}
+
+	return config, nil
+}
+
+// ApplyEnv 使用 ASRT_* 环境变量覆盖配置，未设置的环境变量不影响对应的配置项：
+//
+//	ASRT_ENDPOINTS                 逗号分隔的服务端地址URL列表，设置后清除 Host
+//	ASRT_HOST                      服务端主机名，设置后清除 Endpoints
+//	ASRT_PORT, ASRT_PROTOCOL, ASRT_SUB_PATH
+//	ASRT_BALANCE, ASRT_TIMEOUT, ASRT_RETRIES
+//	ASRT_TLS_CA_FILE, ASRT_TLS_CERT_FILE, ASRT_TLS_KEY_FILE
+//	ASRT_TLS_SERVER_NAME, ASRT_TLS_MIN_VERSION, ASRT_TLS_INSECURE_SKIP_VERIFY
+//	ASRT_TOKEN, ASRT_API_KEY, ASRT_API_KEY_HEADER
+//	ASRT_PROXY                     代理服务器地址，为空时直接连接
+//	ASRT_COMPRESSION               请求压缩算法，例如 gzip
+//	ASRT_PATH_ALL, ASRT_PATH_SPEECH, ASRT_PATH_LANGUAGE
+//	ASRT_API_VERSION, ASRT_API_VERSION_HEADER, ASRT_API_VERSION_QUERY
+//	ASRT_PREPROCESS                逗号分隔的预处理链，例如 "mono,resample=16000,highpass=80"
+func (c *Config) ApplyEnv() error {
+	return c.applyEnv(os.LookupEnv)
+}
+
+// applyEnv 使用 lookup 获取的环境变量覆盖配置
+func (c *Config) applyEnv(lookup func(key string) (string, bool)) error {
+	strs := map[string]*string{
+		"ASRT_PROTOCOL":           &c.Protocol,
+		"ASRT_SUB_PATH":           &c.SubPath,
+		"ASRT_BALANCE":            &c.Balance,
+		"ASRT_TLS_CA_FILE":        &c.TLS.CAFile,
+		"ASRT_TLS_CERT_FILE":      &c.TLS.CertFile,
+		"ASRT_TLS_KEY_FILE":       &c.TLS.KeyFile,
+		"ASRT_TLS_SERVER_NAME":    &c.TLS.ServerName,
+		"ASRT_TLS_MIN_VERSION":    &c.TLS.MinVersion,
+		"ASRT_TOKEN":              &c.Token,
+		"ASRT_API_KEY":            &c.APIKey,
+		"ASRT_API_KEY_HEADER":     &c.APIKeyHeader,
+		"ASRT_COMPRESSION":        &c.Compression,
+		"ASRT_PATH_ALL":           &c.Paths.All,
+		"ASRT_PATH_SPEECH":        &c.Paths.Speech,
+		"ASRT_PATH_LANGUAGE":      &c.Paths.Language,
+		"ASRT_API_VERSION":        &c.APIVersion.Version,
+		"ASRT_API_VERSION_HEADER": &c.APIVersion.Header,
+		"ASRT_API_VERSION_QUERY":  &c.APIVersion.Query,
+	}
+	for key, field := range strs {
+		if value, ok := lookup(key); ok {
+			*field = value
+		}
+	}
+
+	if value, ok := lookup("ASRT_ENDPOINTS"); ok {
+		c.Endpoints = splitList(value)
+		c.Host = ""
+	}
+	if value, ok := lookup("ASRT_HOST"); ok {
+		c.Host = value
+		c.Endpoints = nil
+	}
+	if value, ok := lookup("ASRT_PROXY"); ok {
+		c.Proxy = &value
+	}
+	if value, ok := lookup("ASRT_PORT"); ok {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("error: invalid ASRT_PORT `%s`", value)
+		}
+		c.Port = port
+	}
+	if value, ok := lookup("ASRT_TIMEOUT"); ok {
+		if err := c.Timeout.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("error: invalid ASRT_TIMEOUT `%s`", value)
+		}
+	}
+	if value, ok := lookup("ASRT_RETRIES"); ok {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("error: invalid ASRT_RETRIES `%s`", value)
+		}
+		c.Retries = retries
+	}
+	if value, ok := lookup("ASRT_TLS_INSECURE_SKIP_VERIFY"); ok {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("error: invalid ASRT_TLS_INSECURE_SKIP_VERIFY `%s`", value)
+		}
+		c.TLS.InsecureSkipVerify = skip
+	}
+	if value, ok := lookup("ASRT_PREPROCESS"); ok {
+		preprocess, err := parsePreprocessList(value)
+		if err != nil {
+			return fmt.Errorf("error: invalid ASRT_PREPROCESS `%s`, %w", value, err)
+		}
+		c.Preprocess = preprocess
+	}
+
+	return nil
+}
+
+// splitList 解析逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// parsePreprocessList 解析 "type=value" 形式、逗号分隔的预处理链
+func parsePreprocessList(value string) ([]PreprocessConfig, error) {
+	var preprocess []PreprocessConfig
+	for _, item := range splitList(value) {
+		step := PreprocessConfig{Type: item}
+		if i := strings.Index(item, "="); i >= 0 {
+			number, err := strconv.ParseFloat(item[i+1:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("error: invalid preprocess value `%s`", item)
+			}
+			step = PreprocessConfig{Type: item[:i], Value: number}
+		}
+		preprocess = append(preprocess, step)
+	}
+
+	return preprocess, nil
+}
+
+// endpoints 获取所有服务端地址URL
+func (c *Config) endpoints() []string {
+	if c.Host == "" {
+		return c.Endpoints
+	}
+
+	protocol := c.Protocol
+	if protocol == "" {
+		protocol = "http"
+	}
+	host := c.Host
+	if c.Port != 0 {
+		host = fmt.Sprintf("%s:%d", c.Host, c.Port)
+	}
+	endpoint := url.URL{Scheme: strings.ToLower(protocol), Host: host}
+	if endpoint.Scheme == "http" || endpoint.Scheme == "https" {
+		endpoint.Path = c.SubPath
+	}
+
+	return []string{endpoint.String()}
+}
+
+// balanceStrategy 解析负载均衡策略
+func (c *Config) balanceStrategy() (BalanceStrategy, error) {
+	switch c.Balance {
+	case "", "round-robin":
+		return BalanceRoundRobin, nil
+	case "least-outstanding":
+		return BalanceLeastOutstanding, nil
+	}
+
+	return 0, fmt.Errorf("error: unsupported balance strategy `%s`, must be round-robin or least-outstanding",
+		c.Balance)
+}
+
+// Validate 检查配置是否完整有效，返回描述第一个问题的错误
+func (c *Config) Validate() error {
+	if c.Host != "" && len(c.Endpoints) > 0 {
+		return fmt.Errorf("error: config can not set both endpoints and host")
+	}
+	if c.Host == "" && len(c.Endpoints) == 0 {
+		return fmt.Errorf("error: config has no endpoint, set endpoints or host")
+	}
+	if c.Host != "" {
+		switch strings.ToLower(c.Protocol) {
+		case "", "http", "https", "grpc", "grpcs":
+		default:
+			return fmt.Errorf("error: unsupported protocol `%s`", c.Protocol)
+		}
+		if c.Port < 0 || c.Port > 65535 {
+			return fmt.Errorf("error: invalid port `%d`", c.Port)
+		}
+	}
+	for _, endpoint := range c.Endpoints {
+		if _, err := url.Parse(endpoint); err != nil {
+			return fmt.Errorf("error: invalid endpoint url `%s`, %w", endpoint, err)
+		}
+	}
+	if _, err := c.balanceStrategy(); err != nil {
+		return err
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("error: invalid timeout `%s`", time.Duration(c.Timeout))
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("error: invalid retries `%d`", c.Retries)
+	}
+	if c.TLS.MinVersion != "" {
+		if _, err := parseTLSVersion(c.TLS.MinVersion); err != nil {
+			return err
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("error: tls cert_file and key_file must be set together")
+	}
+	if !c.TLS.empty() {
+		for _, endpoint := range c.endpoints() {
+			scheme := strings.ToLower(strings.SplitN(endpoint, "://", 2)[0])
+			if scheme != "https" && scheme != "grpcs" {
+				return fmt.Errorf("error: tls config requires https or grpcs endpoints, got `%s`", endpoint)
+			}
+		}
+	}
+	if err := c.Paths.check(); err != nil {
+		return err
+	}
+	if c.Proxy != nil {
+		if _, err := parseProxyOption(*c.Proxy); err != nil {
+			return err
+		}
+	}
+	// 经过重采样后预处理链中的采样频率已知，此时滤波的截止频率必须低于奈奎斯特频率
+	frameRate := 0
+	for _, step := range c.Preprocess {
+		if _, err := step.step(); err != nil {
+			return err
+		}
+		if step.Type == "resample" {
+			frameRate = 16000
+			if step.Value != 0 {
+				frameRate = int(step.Value)
+			}
+		} else if (step.Type == "highpass" || step.Type == "lowpass") && frameRate > 0 &&
+			step.Value >= float64(frameRate)/2 {
+			return fmt.Errorf("error: %s cutoff `%v` Hz is not below the nyquist frequency of the resampled rate `%d` Hz",
+				step.Type, step.Value, frameRate)
+		}
+	}
+
+	return nil
+}
+
+// options 获取配置对应的配置项
+func (c *Config) options() []Option {
+	var opts []Option
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(time.Duration(c.Timeout)))
+	}
+	if c.Retries > 0 {
+		policy := DefaultRetryPolicy()
+		policy.MaxAttempts = c.Retries + 1
+		opts = append(opts, WithRetryPolicy(policy))
+	}
+	if !c.TLS.empty() {
+		// Validate 已经检查过版本号
+		minVersion, _ := parseTLSVersion(c.TLS.MinVersion)
+		opts = append(opts, WithTLS(TLSConfig{
+			CAFile:             c.TLS.CAFile,
+			CertFile:           c.TLS.CertFile,
+			KeyFile:            c.TLS.KeyFile,
+			ServerName:         c.TLS.ServerName,
+			MinVersion:         minVersion,
+			InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+		}))
+	}
+	if c.Token != "" {
+		opts = append(opts, WithBearerToken(c.Token))
+	}
+	if c.APIKey != "" {
+		header := c.APIKeyHeader
+		if header == "" {
+			header = "X-API-Key"
+		}
+		opts = append(opts, WithAPIKey(header, c.APIKey))
+	}
+	if c.Paths != (HTTPPaths{}) {
+		opts = append(opts, WithHTTPPaths(c.Paths))
+	}
+	if c.APIVersion != (APIVersion{}) {
+		opts = append(opts, WithAPIVersion(c.APIVersion))
+	}
+	if c.Compression != CompressionNone {
+		opts = append(opts, WithCompression(c.Compression))
+	}
+	if c.Proxy != nil {
+		// Validate 已经检查过代理地址
+		opt, _ := parseProxyOption(*c.Proxy)
+		opts = append(opts, opt)
+	}
+
+	return opts
+}
+
+// NewSpeechRecognizer 校验配置并构造可以直接使用的语音识别实例，
+// 多个服务端时返回 Balancer，配置了预处理链时再用 Preprocessor 包装，
+// opts 在配置文件和环境变量之后应用
+func (c *Config) NewSpeechRecognizer(opts ...Option) (ContextSpeechRecognizer, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 先完成不需要建立连接的部分，出错时不必释放已经构造的实例
+	strategy, err := c.balanceStrategy()
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]PreprocessStep, 0, len(c.Preprocess))
+	for _, config := range c.Preprocess {
+		step, err := config.step()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	opts = append(c.options(), opts...)
+	endpoints := c.endpoints()
+	backends := make([]Backend, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		recognizer, err := NewSpeechRecognizerFromURL(endpoint, opts...)
+		if err != nil {
+			closeBackends(backends)
+			return nil, err
+		}
+		backends = append(backends, Backend{Name: endpoint, Recognizer: recognizer})
+	}
+
+	recognizer := withContext(backends[0].Recognizer)
+	if len(backends) > 1 {
+		balancer, err := NewBalancer(backends, BalancerConfig{Strategy: strategy})
+		if err != nil {
+			closeBackends(backends)
+			return nil, err
+		}
+		recognizer = balancer
+	}
+
+	if len(steps) > 0 {
+		recognizer = NewPreprocessor(recognizer, steps...)
+	}
+
+	return recognizer, nil
+}
+
+// closeBackends 释放已经构造的后端持有的连接
+func closeBackends(backends []Backend) {
+	for _, backend := range backends {
+		_ = closeRecognizer(backend.Recognizer)
+	}
+}
+
+// LoadSpeechRecognizer 从配置文件和 ASRT_* 环境变量加载配置并构造语音识别实例，
+// filename 为空时使用 ASRT_CONFIG 环境变量指定的配置文件，两者都为空时只使用环境变量，
+// 优先级参见 Config
+func LoadSpeechRecognizer(filename string, opts ...Option) (ContextSpeechRecognizer, error) {
+	if filename == "" {
+		filename = os.Getenv("ASRT_CONFIG")
+	}
+
+	config := &Config{}
+	if filename != "" {
+		var err error
+		config, err = LoadConfigFile(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := config.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	return config.NewSpeechRecognizer(opts...)
+}
@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitConfig(t *testing.T) {
+	suite.Run(t, new(TestUnitConfigSuite))
+}
+
+type TestUnitConfigSuite struct {
+	suite.Suite
+}
+
+// clearEnv 在测试期间清除所有 ASRT_* 环境变量，测试结束后恢复
+func (t *TestUnitConfigSuite) clearEnv() {
+	for _, item := range os.Environ() {
+		key := strings.SplitN(item, "=", 2)[0]
+		if !strings.HasPrefix(key, "ASRT_") {
+			continue
+		}
+		value := os.Getenv(key)
+		t.NoError(os.Unsetenv(key))
+		t.T().Cleanup(func() {
+			_ = os.Setenv(key, value)
+		})
+	}
+}
+
+// writeConfig 在临时目录中写入配置文件，返回文件路径
+func (t *TestUnitConfigSuite) writeConfig(name string, content string) string {
+	filename := filepath.Join(t.T().TempDir(), name)
+	t.NoError(ioutil.WriteFile(filename, []byte(content), 0o600))
+
+	return filename
+}
+
+// lookupMap 使用map模拟环境变量
+func lookupMap(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func (t *TestUnitConfigSuite) TestLoadYAML() {
+	config, err := LoadConfigFile(t.writeConfig("asrt.yaml", `
+endpoints:
+  - grpcs://asr-1:20002
+  - grpcs://asr-2:20002
+balance: least-outstanding
+timeout: 5s
+retries: 3
+tls:
+  ca_file: /etc/asrt/ca.pem
+  min_version: "1.3"
+preprocess:
+  - type: mono
+  - type: highpass
+    value: 80
+`))
+	t.NoError(err)
+	t.Equal([]string{"grpcs://asr-1:20002", "grpcs://asr-2:20002"}, config.Endpoints)
+	t.Equal("least-outstanding", config.Balance)
+	t.Equal(5*time.Second, time.Duration(config.Timeout))
+	t.Equal(3, config.Retries)
+	t.Equal("/etc/asrt/ca.pem", config.TLS.CAFile)
+	t.Equal([]PreprocessConfig{{Type: "mono"}, {Type: "highpass", Value: 80}}, config.Preprocess)
+	t.NoError(config.Validate())
+
+	// retries 为失败后的重试次数，不包括第一次请求
+	t.Equal(4, newOptions(config.options()...).retry.MaxAttempts)
+}
+
+func (t *TestUnitConfigSuite) TestLoadJSON() {
+	config, err := LoadConfigFile(t.writeConfig("asrt.json",
+		`{"host": "127.0.0.1", "port": 20001, "protocol": "http", "timeout": "1m30s", "api_key": "key"}`))
+	t.NoError(err)
+	t.Equal(90*time.Second, time.Duration(config.Timeout))
+	t.Equal([]string{"http://127.0.0.1:20001"}, config.endpoints())
+
+	_, err = LoadConfigFile(t.writeConfig("asrt.json", `{"hots": "127.0.0.1"}`))
+	t.Error(err)
+	_, err = LoadConfigFile(t.writeConfig("asrt.yml", "hots: 127.0.0.1\n"))
+	t.Error(err)
+	_, err = LoadConfigFile(t.writeConfig("asrt.toml", `host = "127.0.0.1"`))
+	t.Error(err)
+	_, err = LoadConfigFile(t.writeConfig("asrt.json", `{"timeout": "soon"}`))
+	t.Error(err)
+}
+
+func (t *TestUnitConfigSuite) TestEnvOverridesFile() {
+	config := &Config{Endpoints: []string{"http://file:20001"}, Timeout: Duration(time.Second), Retries: 2}
+	err := config.applyEnv(lookupMap(map[string]string{
+		"ASRT_HOST":       "env",
+		"ASRT_PORT":       "20002",
+		"ASRT_PROTOCOL":   "grpc",
+		"ASRT_TIMEOUT":    "3s",
+		"ASRT_PREPROCESS": "mono, resample=8000",
+		"ASRT_PROXY":      "",
+	}))
+	t.NoError(err)
+	t.Equal("", *config.Proxy)
+	t.Nil(config.Endpoints)
+	t.Equal([]string{"grpc://env:20002"}, config.endpoints())
+	t.Equal(3*time.Second, time.Duration(config.Timeout))
+	t.Equal(2, config.Retries)
+	t.Equal([]PreprocessConfig{{Type: "mono"}, {Type: "resample", Value: 8000}}, config.Preprocess)
+
+	err = config.applyEnv(lookupMap(map[string]string{"ASRT_ENDPOINTS": "grpc://a:1,grpc://b:2"}))
+	t.NoError(err)
+	t.Equal("", config.Host)
+	t.Equal([]string{"grpc://a:1", "grpc://b:2"}, config.endpoints())
+
+	t.Error(config.applyEnv(lookupMap(map[string]string{"ASRT_RETRIES": "many"})))
+	t.Error(config.applyEnv(lookupMap(map[string]string{"ASRT_PREPROCESS": "gain=loud"})))
+}
+
+func (t *TestUnitConfigSuite) TestValidate() {
+	proxy := "ftp://proxy:21"
+	invalid := []Config{
+		{},
+		{Host: "a", Endpoints: []string{"http://b"}},
+		{Host: "a", Protocol: "ftp"},
+		{Host: "a", Port: 70000},
+		{Host: "a", Balance: "random"},
+		{Host: "a", Retries: -1},
+		{Host: "a", TLS: TLSFileConfig{CAFile: "ca.pem"}},
+		{Endpoints: []string{"grpcs://a", "grpc://b"}, TLS: TLSFileConfig{ServerName: "asr"}},
+		{Host: "a", TLS: TLSFileConfig{MinVersion: "1.1"}},
+		{Host: "a", TLS: TLSFileConfig{CertFile: "client.pem"}},
+		{Host: "a", Proxy: &proxy},
+		{Host: "a", Preprocess: []PreprocessConfig{{Type: "reverb"}}},
+		{Host: "a", Preprocess: []PreprocessConfig{{Type: "lowpass"}}},
+		{Host: "a", Preprocess: []PreprocessConfig{{Type: "normalize", Value: 3}}},
+		{Host: "a", Preprocess: []PreprocessConfig{{Type: "resample", Value: 8000}, {Type: "lowpass", Value: 6000}}},
+		{Host: "a", Preprocess: []PreprocessConfig{{Type: "resample"}, {Type: "highpass", Value: 8000}}},
+	}
+	for i := range invalid {
+		t.Error(invalid[i].Validate(), "config %d", i)
+	}
+
+	// 重采样之前采样频率未知，截止频率由 PreprocessLowPass 和 PreprocessHighPass 在处理时检查
+	valid := Config{Host: "a", Preprocess: []PreprocessConfig{{Type: "lowpass", Value: 6000},
+		{Type: "resample", Value: 16000}, {Type: "lowpass", Value: 6000}}}
+	t.NoError(valid.Validate())
+}
+
+func (t *TestUnitConfigSuite) TestNewSpeechRecognizer() {
+	_, port := startFakeHTTPServer(t.T(), requireHeader("X-API-Key", "key"))
+	config, err := LoadConfigFile(t.writeConfig("asrt.yaml",
+		"host: 127.0.0.1\nport: "+port+"\napi_key: key\ntimeout: 5s\n"))
+	t.NoError(err)
+
+	// 测试服务端未使用TLS，需要显式允许以明文携带API Key
+	_, err = config.NewSpeechRecognizer()
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+	recognizer, err := config.NewSpeechRecognizer(WithInsecureAuth())
+	t.NoError(err)
+	t.IsType(&HTTPSpeechRecognizer{}, recognizer)
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3", "hao3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+
+	// 多个服务端和预处理链
+	config = &Config{
+		Endpoints:  []string{"http://127.0.0.1:" + port, "http://localhost:" + port},
+		APIKey:     "key",
+		Preprocess: []PreprocessConfig{{Type: "mono"}, {Type: "resample"}},
+	}
+	recognizer, err = config.NewSpeechRecognizer(WithInsecureAuth())
+	t.NoError(err)
+	preprocessor, ok := recognizer.(*Preprocessor)
+	t.True(ok)
+	t.IsType(&Balancer{}, preprocessor.next)
+	_, err = recognizer.RecogniteLanguage([]string{"ni3", "hao3"})
+	t.NoError(err)
+}
+
+func (t *TestUnitConfigSuite) TestLoadSpeechRecognizer() {
+	t.clearEnv()
+	port := startFakeGRPCServer(t.T(), nil)
+	filename := t.writeConfig("asrt.json", `{"endpoints": ["grpc://127.0.0.1:`+port+`"], "retries": 2}`)
+
+	recognizer, err := LoadSpeechRecognizer(filename)
+	t.NoError(err)
+	grpcRecognizer, ok := recognizer.(*GRPCSpeechRecognizer)
+	t.True(ok)
+	defer grpcRecognizer.Close()
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3", "hao3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+
+	_, err = LoadSpeechRecognizer(filepath.Join(t.T().TempDir(), "missing.yaml"))
+	t.Error(err)
+}
+
+func (t *TestUnitConfigSuite) TestNewSpeechRecognizerEndpointError() {
+	// 第二个服务端地址构造失败时返回错误，已经构造的第一个实例被关闭
+	config := &Config{Endpoints: []string{"grpc://127.0.0.1:20002", "grpc://127.0.0.1:20002/asr"}}
+	t.NoError(config.Validate())
+	recognizer, err := config.NewSpeechRecognizer()
+	t.Error(err)
+	t.Nil(recognizer)
+}
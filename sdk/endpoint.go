@@ -0,0 +1,225 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 各协议在URL中未指定端口时使用的默认端口
+var defaultEndpointPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"grpc":  "20002",
+	"grpcs": "20002",
+}
+
+// NewSpeechRecognizerFromURL 根据服务端地址URL构造语音识别类实例对象，支持以下形式：
+//
+//	http://127.0.0.1:20001           HTTP+JSON协议，路径作为 SubPath，例如 https://gw/asrt/v1
+//	grpcs://asr.internal:20002       gRPC协议，grpcs使用TLS
+//	unix:///run/asrt.sock            通过Unix域套接字连接的gRPC协议
+//
+// 未指定端口时，http和https使用80和443端口，grpc和grpcs使用ASRT默认的20002端口。
+// URL的查询参数可以设置以下配置项，在 opts 之后应用：
+//
+//	timeout=5s                       单次请求的超时时间，参见 WithTimeout
+//	retries=3                        使用默认重试策略，失败后最多重试3次，为0时不重试
+//	ca, cert, key                    CA证书文件、mTLS客户端证书和私钥文件，参见 TLSConfig
+//	server_name, min_tls             证书校验使用的主机名、最低TLS版本(1.2或1.3)
+//	insecure_skip_verify=true        不校验服务端证书
+//	token                            Bearer访问令牌，参见 WithBearerToken
+//	api_key, api_key_header          API Key及其请求头名称，请求头默认为 X-API-Key
+//	proxy                            代理服务器地址，需要URL编码，为空表示直接连接，参见 WithProxy
+//	compression=gzip                 压缩请求，参见 WithCompression
+//	path_all, path_speech, path_language    HTTP协议各接口的路径，参见 WithHTTPPaths
+//	api_version, api_version_header, api_version_query    HTTP协议请求携带的API版本，参见 WithAPIVersion
+//
+// TLS相关的查询参数只能用于https和grpcs协议。
+// URL不合法或包含不支持的查询参数时返回描述原因的错误
+func NewSpeechRecognizerFromURL(rawURL string, opts ...Option) (ContextSpeechRecognizer, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid endpoint url `%s`, %w", rawURL, err)
+	}
+
+	queryOptions, err := parseEndpointQuery(endpoint.Query())
+	if err != nil {
+		return nil, err
+	}
+	base := BaseSpeechRecognizer{
+		Protocol: strings.ToLower(endpoint.Scheme),
+		options:  newOptions(append(append([]Option{}, opts...), queryOptions...)...),
+	}
+
+	if base.Protocol != "https" && base.Protocol != "grpcs" {
+		for _, key := range tlsQueryParameters {
+			if _, ok := endpoint.Query()[key]; ok {
+				return nil, fmt.Errorf("error: tls query parameter `%s` requires https or grpcs, got `%s`",
+					key, endpoint.Scheme)
+			}
+		}
+	}
+
+	switch base.Protocol {
+	case "unix":
+		if endpoint.Host != "" || endpoint.Path == "" {
+			return nil, fmt.Errorf("error: unix endpoint url must be like `unix:///path/to/socket`, got `%s`", rawURL)
+		}
+		base.Protocol = "grpc"
+		base.Host = endpoint.Path
+		return newGRPCSpeechRecognizer(base, "unix://"+endpoint.Path)
+	case "http", "https", "grpc", "grpcs":
+	default:
+		return nil, fmt.Errorf("error: unsupported endpoint protocol `%s`", endpoint.Scheme)
+	}
+
+	base.Host = endpoint.Hostname()
+	base.Port = endpoint.Port()
+	if base.Host == "" {
+		return nil, fmt.Errorf("error: endpoint url `%s` has no host", rawURL)
+	}
+	if base.Port == "" {
+		base.Port = defaultEndpointPorts[base.Protocol]
+	}
+
+	if base.Protocol == "http" || base.Protocol == "https" {
+		return newHTTPSpeechRecognizer(base, strings.TrimSuffix(endpoint.Path, "/"))
+	}
+
+	if endpoint.Path != "" && endpoint.Path != "/" {
+		return nil, fmt.Errorf("error: grpc endpoint url `%s` can not have a path", rawURL)
+	}
+	return newGRPCSpeechRecognizer(base, fmt.Sprintf("%s:%s", base.Host, base.Port))
+}
+
+// tlsQueryParameters TLS相关的查询参数
+var tlsQueryParameters = []string{"ca", "cert", "key", "server_name", "min_tls", "insecure_skip_verify"}
+
+// parseEndpointQuery 将URL查询参数转换为配置项
+func parseEndpointQuery(query url.Values) ([]Option, error) {
+	var opts []Option
+	var tlsConfig TLSConfig
+	hasTLS := false
+	apiKeyHeader := "X-API-Key"
+	var paths HTTPPaths
+	var apiVersion APIVersion
+
+	for key := range query {
+		value := query.Get(key)
+		switch key {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return nil, fmt.Errorf("error: invalid timeout `%s` in endpoint url", value)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "retries":
+			retries, err := strconv.Atoi(value)
+			if err != nil || retries < 0 {
+				return nil, fmt.Errorf("error: invalid retries `%s` in endpoint url", value)
+			}
+			policy := DefaultRetryPolicy()
+			policy.MaxAttempts = retries + 1
+			opts = append(opts, WithRetryPolicy(policy))
+		case "ca":
+			tlsConfig.CAFile, hasTLS = value, true
+		case "cert":
+			tlsConfig.CertFile, hasTLS = value, true
+		case "key":
+			tlsConfig.KeyFile, hasTLS = value, true
+		case "server_name":
+			tlsConfig.ServerName, hasTLS = value, true
+		case "min_tls":
+			version, err := parseTLSVersion(value)
+			if err != nil {
+				return nil, fmt.Errorf("error: invalid min_tls `%s` in endpoint url, must be 1.2 or 1.3", value)
+			}
+			tlsConfig.MinVersion, hasTLS = version, true
+		case "insecure_skip_verify":
+			skip, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("error: invalid insecure_skip_verify `%s` in endpoint url", value)
+			}
+			tlsConfig.InsecureSkipVerify, hasTLS = skip, true
+		case "token":
+			opts = append(opts, WithBearerToken(value))
+		case "proxy":
+			opt, err := parseProxyOption(value)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, opt)
+		case "path_all":
+			paths.All = value
+		case "path_speech":
+			paths.Speech = value
+		case "path_language":
+			paths.Language = value
+		case "api_version":
+			apiVersion.Version = value
+		case "api_version_header":
+			apiVersion.Header = value
+		case "api_version_query":
+			apiVersion.Query = value
+		case "compression":
+			opts = append(opts, WithCompression(value))
+		case "api_key_header":
+			apiKeyHeader = value
+		case "api_key":
+			// 需要等 api_key_header 解析完成，在循环结束后处理
+		default:
+			return nil, fmt.Errorf("error: unsupported query parameter `%s` in endpoint url", key)
+		}
+	}
+
+	if apiKey := query.Get("api_key"); apiKey != "" {
+		opts = append(opts, WithAPIKey(apiKeyHeader, apiKey))
+	}
+	if hasTLS {
+		opts = append(opts, WithTLS(tlsConfig))
+	}
+	if paths != (HTTPPaths{}) {
+		if err := paths.check(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithHTTPPaths(paths))
+	}
+	if apiVersion != (APIVersion{}) {
+		opts = append(opts, WithAPIVersion(apiVersion))
+	}
+
+	return opts, nil
+}
+
+// parseTLSVersion 解析 "1.2" 或 "1.3" 形式的TLS版本号
+func parseTLSVersion(value string) (uint16, error) {
+	switch value {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("error: unsupported tls version `%s`, must be 1.2 or 1.3", value)
+}
+
+// parseProxyOption 解析代理服务器地址，为空时表示直接连接
+func parseProxyOption(value string) (Option, error) {
+	if value == "" {
+		return WithProxy(nil), nil
+	}
+
+	proxyURL, err := url.Parse(value)
+	if err == nil {
+		err = checkProxy(proxyURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid proxy `%s`, %w", value, err)
+	}
+
+	return WithProxy(proxyURL), nil
+}
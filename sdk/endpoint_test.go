@@ -0,0 +1,148 @@
+package sdk
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+	grpcClient "github.com/nl8590687/asrt-sdk-go/grpc"
+)
+
+func TestUnitEndpoint(t *testing.T) {
+	suite.Run(t, new(TestUnitEndpointSuite))
+}
+
+type TestUnitEndpointSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitEndpointSuite) TestParse() {
+	tests := []struct {
+		url      string
+		protocol string
+		host     string
+		port     string
+		subPath  string
+	}{
+		{url: "http://127.0.0.1:20001", protocol: "http", host: "127.0.0.1", port: "20001"},
+		{url: "https://gw/asrt/v1/", protocol: "https", host: "gw", port: "443", subPath: "/asrt/v1"},
+		{url: "HTTP://gw", protocol: "http", host: "gw", port: "80"},
+		{url: "grpcs://asr.internal:20002", protocol: "grpcs", host: "asr.internal", port: "20002"},
+		{url: "grpc://[::1]", protocol: "grpc", host: "::1", port: "20002"},
+	}
+
+	for _, tt := range tests {
+		recognizer, err := NewSpeechRecognizerFromURL(tt.url)
+		t.NoError(err, tt.url)
+
+		switch r := recognizer.(type) {
+		case *HTTPSpeechRecognizer:
+			t.Equal(tt.protocol, r.Protocol, tt.url)
+			t.Equal(tt.host, r.Host, tt.url)
+			t.Equal(tt.port, r.Port, tt.url)
+			t.Equal(tt.subPath, r.SubPath, tt.url)
+		case *GRPCSpeechRecognizer:
+			t.Equal(tt.protocol, r.Protocol, tt.url)
+			t.Equal(tt.host, r.Host, tt.url)
+			t.Equal(tt.port, r.Port, tt.url)
+			r.Close()
+		default:
+			t.Failf("unexpected recognizer type", "%s: %T", tt.url, recognizer)
+		}
+	}
+}
+
+func (t *TestUnitEndpointSuite) TestInvalid() {
+	invalid := []string{
+		"ftp://127.0.0.1:21",
+		"http://:20001",
+		"grpc://127.0.0.1:20002/path",
+		"unix://host/run/asrt.sock",
+		"http://127.0.0.1?timeout=fast",
+		"http://127.0.0.1?retries=-1",
+		"http://127.0.0.1?min_tls=1.0",
+		"http://127.0.0.1?unknown=1",
+		"https://127.0.0.1?ca=/nonexistent/ca.pem",
+		"http://127.0.0.1?ca=ca.pem",
+		"grpc://127.0.0.1?insecure_skip_verify=true",
+		"unix:///run/asrt.sock?server_name=asr",
+		"://bad",
+	}
+
+	for _, rawURL := range invalid {
+		recognizer, err := NewSpeechRecognizerFromURL(rawURL)
+		t.Error(err, rawURL)
+		t.Nil(recognizer, rawURL)
+	}
+
+	t.Nil(GetSpeechRecognizer("127.0.0.1", "21", "ftp"))
+}
+
+func (t *TestUnitEndpointSuite) TestQueryOptions() {
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/slow/language" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fakeHTTPHandler(w, r)
+	})
+
+	recognizer, err := NewSpeechRecognizerFromURL(
+		"http://127.0.0.1:"+port+"/?token=secret&api_key=key&api_key_header=X-Key", WithInsecureAuth())
+	t.NoError(err)
+	_, err = recognizer.RecogniteLanguage(nil)
+	t.NoError(err)
+
+	recognizer, err = NewSpeechRecognizerFromURL(
+		"http://127.0.0.1:"+port+"/slow?token=secret&api_key=key&api_key_header=X-Key&timeout=50ms",
+		WithInsecureAuth())
+	t.NoError(err)
+	_, err = recognizer.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrTransport)
+}
+
+func (t *TestUnitEndpointSuite) TestRetries() {
+	tests := []struct {
+		retries  string
+		attempts int
+	}{
+		{retries: "0", attempts: 1},
+		{retries: "1", attempts: 2},
+		{retries: "3", attempts: 4},
+	}
+
+	for _, tt := range tests {
+		recognizer, err := NewSpeechRecognizerFromURL("http://127.0.0.1?retries=" + tt.retries)
+		t.NoError(err, tt.retries)
+		t.Equal(tt.attempts, recognizer.(*HTTPSpeechRecognizer).options.retry.MaxAttempts, tt.retries)
+	}
+}
+
+func (t *TestUnitEndpointSuite) TestUnixSocket() {
+	socket := filepath.Join(t.T().TempDir(), "asrt.sock")
+	listener, err := net.Listen("unix", socket)
+	t.NoError(err)
+	server := grpc.NewServer()
+	grpcClient.RegisterAsrtGrpcServiceServer(server, &fakeGRPCServer{text: "你好"})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	recognizer, err := NewSpeechRecognizerFromURL("unix://" + socket)
+	t.NoError(err)
+	defer recognizer.(*GRPCSpeechRecognizer).Close()
+
+	rsp, err := recognizer.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+}
@@ -0,0 +1,158 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitErrorMapping(t *testing.T) {
+	suite.Run(t, new(TestUnitErrorMappingSuite))
+}
+
+type TestUnitErrorMappingSuite struct {
+	suite.Suite
+}
+
+// allCategories 全部错误分类，用于检查错误不属于预期以外的分类
+var allCategories = []error{
+	common.ErrClient, common.ErrClientFormat, common.ErrUnsupportedConfig,
+	common.ErrServer, common.ErrTransport, common.ErrPartialResult,
+}
+
+// checkCategories 检查 err 恰好属于 want 中的错误分类
+func (t *TestUnitErrorMappingSuite) checkCategories(err error, want ...error) {
+	for _, category := range allCategories {
+		expected := false
+		for _, target := range want {
+			expected = expected || target == category
+		}
+		t.Equal(expected, errors.Is(err, category), "%v is %v", err, category)
+	}
+}
+
+func (t *TestUnitErrorMappingSuite) TestTranslateGRPCError() {
+	tests := []struct {
+		code codes.Code
+		want []error
+	}{
+		{code: codes.InvalidArgument, want: []error{common.ErrClient, common.ErrClientFormat}},
+		{code: codes.Unimplemented, want: []error{common.ErrClient, common.ErrUnsupportedConfig}},
+		{code: codes.OutOfRange, want: []error{common.ErrClient, common.ErrUnsupportedConfig}},
+		{code: codes.PermissionDenied, want: []error{common.ErrClient}},
+		{code: codes.NotFound, want: []error{common.ErrClient}},
+		{code: codes.Internal, want: []error{common.ErrServer}},
+		{code: codes.Unknown, want: []error{common.ErrServer}},
+		{code: codes.Unavailable, want: []error{common.ErrTransport}},
+		{code: codes.DeadlineExceeded, want: []error{common.ErrTransport}},
+		{code: codes.ResourceExhausted, want: []error{common.ErrTransport}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func() {
+			err := translateGRPCError("all", status.Error(tt.code, "failed"))
+			t.checkCategories(err, tt.want...)
+
+			var apiError *common.APIError
+			if errors.As(err, &apiError) {
+				t.Equal(tt.code, apiError.GRPCCode)
+				t.Equal("failed", apiError.Message)
+			}
+		})
+	}
+
+	err := translateGRPCError("all", errors.New("connection reset"))
+	t.checkCategories(err, common.ErrTransport)
+}
+
+func (t *TestUnitErrorMappingSuite) TestGRPCStatus() {
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad samples")
+	}
+	port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.checkCategories(err, common.ErrClient, common.ErrClientFormat)
+}
+
+func (t *TestUnitErrorMappingSuite) TestHTTPStatus() {
+	tests := []struct {
+		name       string
+		httpStatus int
+		body       string
+		want       []error
+	}{
+		{name: "bad request", httpStatus: 400, want: []error{common.ErrClient}},
+		{name: "not found", httpStatus: 404, want: []error{common.ErrClient}},
+		{name: "server error", httpStatus: 500, want: []error{common.ErrServer}},
+		{name: "unavailable", httpStatus: 503, body: "<html>busy</html>", want: []error{common.ErrServer}},
+		{
+			name: "asrt status in body", httpStatus: 400,
+			body: `{"status_code": 400001, "status_message": "bad format"}`,
+			want: []error{common.ErrClient, common.ErrClientFormat},
+		},
+		{
+			name: "asrt error with http ok", httpStatus: 200,
+			body: `{"status_code": 500001, "status_message": "running error"}`,
+			want: []error{common.ErrServer},
+		},
+		{name: "invalid body", httpStatus: 200, body: "not json", want: []error{common.ErrServer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.httpStatus)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+			_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+			t.checkCategories(err, tt.want...)
+			var apiError *common.APIError
+			t.True(errors.As(err, &apiError))
+			t.Equal(tt.httpStatus, apiError.HTTPStatus)
+		})
+	}
+}
+
+func (t *TestUnitErrorMappingSuite) TestHTTPPartialResult() {
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(common.AsrtAPIResponse{
+			StatusCode:    common.APIStatusCodePartOK,
+			StatucMesaage: "part ok",
+			Result:        "你",
+		})
+	})
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3", "hao3"})
+	t.checkCategories(err, common.ErrPartialResult)
+	t.Equal("你", rsp.Result)
+}
+
+func (t *TestUnitErrorMappingSuite) TestLocalValidation() {
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", "1", "http", "")
+
+	_, err := recognizer.RecogniteSpeech(make([]byte, wavDataMaxLength+2), 16000, 1, 2)
+	t.checkCategories(err, common.ErrClient, common.ErrClientFormat)
+
+	_, err = recognizer.RecogniteLong(make([]byte, 3200), 8000, 1, 2)
+	t.checkCategories(err, common.ErrClient, common.ErrUnsupportedConfig)
+}
@@ -0,0 +1,287 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// defaultDemoteAfter 主实例连续失败多少次后切换到备用实例
+	defaultDemoteAfter = 3
+	// defaultProbeInterval 切换到备用实例后，试探主实例是否恢复的默认间隔
+	defaultProbeInterval = 30 * time.Second
+)
+
+// FailoverConfig 主备切换配置，零值表示使用默认值
+type FailoverConfig struct {
+	// DemoteAfter 主实例连续失败该次数后切换到备用实例，默认3
+	DemoteAfter int
+	// ProbeInterval 切换到备用实例后，每隔该时间在后台对主实例做一次健康检查，通过则切换回主实例，默认30s。
+	// 试探只在有请求时发起，请求本身始终发往备用实例
+	ProbeInterval time.Duration
+	// ProbeTimeout 单次试探的超时时间，默认5s
+	ProbeTimeout time.Duration
+	// IsFailure 判断一次请求的错误是否计为失败，默认只有传输错误和服务端错误计为失败
+	IsFailure func(err error) bool
+	// OnSwitch 在主备实例之间切换时的回调，demoted 为true表示切换到了备用实例
+	OnSwitch func(demoted bool, err error)
+}
+
+// Failover 在主备两个语音识别实例之间自动切换的语音识别实例，
+// 典型用法是组合同一个ASRT服务端的gRPC和HTTP接口，参见 NewTransportFailover
+//
+// 主实例请求失败时，本次请求会立即改由备用实例完成，调用方不会感知到主实例的故障。
+// 不再使用时调用 Close 释放主备实例持有的连接
+type Failover struct {
+	recognizerMixin
+
+	primary   ContextSpeechRecognizer
+	secondary ContextSpeechRecognizer
+	config    FailoverConfig
+	now       func() time.Time
+
+	mutex               sync.Mutex
+	demoted             bool
+	consecutiveFailures int
+	lastProbe           time.Time
+	probing             bool
+	probes              sync.WaitGroup
+	closed              bool
+}
+
+// NewFailover 构造一个优先使用 primary，故障时切换到 secondary 的语音识别实例
+func NewFailover(primary ISpeechRecognizer, secondary ISpeechRecognizer, config FailoverConfig) *Failover {
+	if config.DemoteAfter <= 0 {
+		config.DemoteAfter = defaultDemoteAfter
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = defaultProbeInterval
+	}
+	if config.ProbeTimeout <= 0 {
+		config.ProbeTimeout = defaultProbeTimeout
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isBreakerFailure
+	}
+
+	failover := &Failover{
+		primary:   withContext(primary),
+		secondary: withContext(secondary),
+		config:    config,
+		now:       time.Now,
+	}
+	failover.recognizerMixin = recognizerMixin{self: failover}
+
+	return failover
+}
+
+// NewTransportFailover 构造一个组合同一个ASRT服务端的gRPC和HTTP接口的主备切换实例，
+// primary 为主实例使用的协议，备用实例使用另一种传输方式，且与主实例同样是否加密，
+// 例如 primary 为 "grpcs" 时备用实例使用 "https"。协议不支持或任一实例构造失败时返回错误
+func NewTransportFailover(host string, httpPort string, grpcPort string, primary string, config FailoverConfig,
+	opts ...Option,
+) (*Failover, error) {
+	var httpProtocol, grpcProtocol string
+	switch strings.ToLower(primary) {
+	case "grpc", "http":
+		httpProtocol, grpcProtocol = "http", "grpc"
+	case "grpcs", "https":
+		httpProtocol, grpcProtocol = "https", "grpcs"
+	default:
+		return nil, fmt.Errorf("error: unsupported protocol `%s`", primary)
+	}
+
+	httpRecognizer, err := newHTTPSpeechRecognizer(BaseSpeechRecognizer{
+		Host:     host,
+		Port:     httpPort,
+		Protocol: httpProtocol,
+		options:  newOptions(opts...),
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	grpcRecognizer, err := newGRPCSpeechRecognizer(BaseSpeechRecognizer{
+		Host:     host,
+		Port:     grpcPort,
+		Protocol: grpcProtocol,
+		options:  newOptions(opts...),
+	}, fmt.Sprintf("%s:%s", host, grpcPort))
+	if err != nil {
+		httpRecognizer.CloseIdleConnections()
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.ToLower(primary), "grpc") {
+		return NewFailover(grpcRecognizer, httpRecognizer, config), nil
+	}
+
+	return NewFailover(httpRecognizer, grpcRecognizer, config), nil
+}
+
+// Demoted 获取当前是否已经切换到备用实例
+func (f *Failover) Demoted() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.demoted
+}
+
+// RecogniteContext 经过主备切换调用ASRT语音识别
+func (f *Failover) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return f.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteSpeechContext 经过主备切换调用ASRT语音识别声学模型
+func (f *Failover) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return f.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteLanguageContext 经过主备切换调用ASRT语音识别语言模型
+func (f *Failover) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return f.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteLanguageContext(ctx, sequencePinyin)
+	})
+}
+
+// call 按当前状态选择主实例或备用实例调用 fn，主实例失败时改用备用实例
+func (f *Failover) call(ctx context.Context,
+	fn func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	if !f.usePrimary() {
+		return fn(ctx, f.secondary)
+	}
+
+	rsp, err := fn(ctx, f.primary)
+	failed := err != nil && !errors.Is(err, context.Canceled) && f.config.IsFailure(err)
+	f.recordPrimary(err)
+	if !failed || ctx.Err() != nil {
+		return rsp, err
+	}
+
+	return fn(ctx, f.secondary)
+}
+
+// usePrimary 判断本次请求是否发往主实例，已切换到备用实例时请求都发往备用实例，
+// 并每隔 ProbeInterval 在后台试探一次主实例是否恢复
+func (f *Failover) usePrimary() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.demoted {
+		return true
+	}
+	if !f.closed && !f.probing && f.now().Sub(f.lastProbe) >= f.config.ProbeInterval {
+		f.probing = true
+		f.lastProbe = f.now()
+		f.probes.Add(1)
+		go f.probe()
+	}
+
+	return false
+}
+
+// probe 在后台对主实例做一次健康检查，主实例实现了 HealthChecker 时使用其 HealthCheck，
+// 否则发送一次很小的语言模型识别请求，检查通过时切换回主实例
+func (f *Failover) probe() {
+	defer f.probes.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.config.ProbeTimeout)
+	defer cancel()
+	var result HealthStatus
+	if checker, ok := f.primary.(HealthChecker); ok {
+		result = checker.HealthCheck(ctx)
+	} else {
+		result = healthRoundTrip(ctx, f.primary.RecogniteLanguageContext)
+	}
+
+	f.mutex.Lock()
+	var notify func()
+	defer func() {
+		f.mutex.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+
+	f.probing = false
+	f.lastProbe = f.now()
+	if !result.Ready || !f.demoted {
+		return
+	}
+	f.demoted = false
+	f.consecutiveFailures = 0
+	notify = f.switchNotify(false, nil)
+}
+
+// recordPrimary 记录一次主实例请求的结果，连续失败达到 DemoteAfter 次时切换到备用实例
+func (f *Failover) recordPrimary(err error) {
+	f.mutex.Lock()
+	var notify func()
+	defer func() {
+		f.mutex.Unlock()
+		if notify != nil {
+			notify()
+		}
+	}()
+
+	// 切换前发出的请求在切换后才返回时不再计数，调用方主动取消的请求不能说明主实例是否可用
+	if f.demoted || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	// 不计为失败的错误（例如客户端请求错误）不累计失败次数
+	if err == nil || !f.config.IsFailure(err) {
+		f.consecutiveFailures = 0
+		return
+	}
+
+	f.consecutiveFailures += 1
+	if f.consecutiveFailures >= f.config.DemoteAfter {
+		f.demoted = true
+		f.consecutiveFailures = 0
+		f.lastProbe = f.now()
+		notify = f.switchNotify(true, err)
+	}
+}
+
+// Close 等待进行中的后台试探结束后释放主备实例持有的连接，参见 closeRecognizer，
+// 两个实例都会被关闭，返回遇到的第一个错误
+func (f *Failover) Close() error {
+	f.mutex.Lock()
+	f.closed = true
+	f.mutex.Unlock()
+	f.probes.Wait()
+
+	err := closeRecognizer(f.primary)
+	if secondaryErr := closeRecognizer(f.secondary); err == nil {
+		err = secondaryErr
+	}
+
+	return err
+}
+
+// switchNotify 返回需要在锁外调用的切换回调
+func (f *Failover) switchNotify(demoted bool, err error) func() {
+	if f.config.OnSwitch == nil {
+		return nil
+	}
+
+	return func() {
+		f.config.OnSwitch(demoted, err)
+	}
+}
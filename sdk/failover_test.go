@@ -0,0 +1,233 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitFailover(t *testing.T) {
+	suite.Run(t, new(TestUnitFailoverSuite))
+}
+
+type TestUnitFailoverSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitFailoverSuite) TestDemoteAndPromote() {
+	primaryDown := true
+	primary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		if primaryDown {
+			return nil, &common.TransportError{Op: "language", Err: errors.New("connection refused")}
+		}
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK, Result: "primary"}, nil
+	})
+	secondary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK, Result: "secondary"}, nil
+	})
+
+	var switches []bool
+	failover := NewFailover(primary, secondary, FailoverConfig{
+		DemoteAfter:   2,
+		ProbeInterval: time.Minute,
+		OnSwitch: func(demoted bool, err error) {
+			switches = append(switches, demoted)
+		},
+	})
+	now := time.Unix(1000, 0)
+	failover.now = func() time.Time { return now }
+
+	// 主实例失败的请求透明地由备用实例完成
+	for i := 0; i < 2; i += 1 {
+		rsp, err := failover.RecogniteLanguage(nil)
+		t.NoError(err)
+		t.Equal("secondary", rsp.Result)
+	}
+	t.True(failover.Demoted())
+	t.Equal(2, primary.calls)
+
+	_, _ = failover.RecogniteLanguage(nil)
+	failover.probes.Wait()
+	t.Equal(2, primary.calls)
+
+	// 试探在后台进行，失败时继续使用备用实例
+	now = now.Add(time.Minute)
+	rsp, err := failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("secondary", rsp.Result)
+	failover.probes.Wait()
+	t.Equal(3, primary.calls)
+	t.True(failover.Demoted())
+
+	// 触发试探的请求仍由备用实例完成，试探成功后才切换回主实例
+	primaryDown = false
+	now = now.Add(time.Minute)
+	rsp, err = failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("secondary", rsp.Result)
+	failover.probes.Wait()
+	t.Equal(4, primary.calls)
+	t.False(failover.Demoted())
+
+	rsp, err = failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("primary", rsp.Result)
+	t.Equal([]bool{true, false}, switches)
+}
+
+func (t *TestUnitFailoverSuite) TestProbeNotPromotedWithoutSuccess() {
+	var primaryErr error = &common.TransportError{Op: "language", Err: errors.New("connection refused")}
+	primary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		if primaryErr != nil {
+			return nil, primaryErr
+		}
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK, Result: "primary"}, nil
+	})
+	secondary := okRecognizer()
+
+	var switches []bool
+	failover := NewFailover(primary, secondary, FailoverConfig{
+		DemoteAfter:   1,
+		ProbeInterval: time.Minute,
+		OnSwitch: func(demoted bool, err error) {
+			switches = append(switches, demoted)
+		},
+	})
+	now := time.Unix(1000, 0)
+	failover.now = func() time.Time { return now }
+
+	_, err := failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.True(failover.Demoted())
+
+	// 试探请求被取消，或返回不计为失败的错误，都不能证明主实例已经恢复
+	for _, probeErr := range []error{
+		&common.TransportError{Op: "language", Err: context.Canceled},
+		&common.APIError{StatusCode: common.APIStatusCodeClientErrorFormat, HTTPStatus: 400},
+	} {
+		primaryErr = probeErr
+		now = now.Add(time.Minute)
+		calls := primary.calls
+		_, err = failover.RecogniteLanguage(nil)
+		t.NoError(err)
+		failover.probes.Wait()
+		t.Equal(calls+1, primary.calls)
+		t.True(failover.Demoted())
+		t.False(failover.probing)
+	}
+	t.Equal([]bool{true}, switches)
+
+	primaryErr = nil
+	now = now.Add(time.Minute)
+	_, err = failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	failover.probes.Wait()
+	t.False(failover.Demoted())
+	t.Equal([]bool{true, false}, switches)
+	rsp, err := failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("primary", rsp.Result)
+}
+
+func (t *TestUnitFailoverSuite) TestProbeInBackground() {
+	primary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		<-ctx.Done()
+		return nil, &common.TransportError{Op: "language", Err: ctx.Err()}
+	})
+	failover := NewFailover(primary, okRecognizer(), FailoverConfig{
+		DemoteAfter:   1,
+		ProbeInterval: time.Minute,
+		ProbeTimeout:  200 * time.Millisecond,
+	})
+	now := time.Unix(1000, 0)
+	failover.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := failover.RecogniteLanguageContext(ctx, nil)
+	t.Error(err)
+	t.True(failover.Demoted())
+
+	// 主实例无响应时，触发试探的请求不会等待试探结束
+	now = now.Add(time.Minute)
+	start := time.Now()
+	_, err = failover.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Less(int64(time.Since(start)), int64(200*time.Millisecond))
+	failover.probes.Wait()
+	t.False(failover.probing)
+	t.True(failover.Demoted())
+	t.Equal(2, primary.calls)
+}
+
+func (t *TestUnitFailoverSuite) TestClose() {
+	primary := &closableRecognizer{}
+	grpcRecognizer := NewGRPCSpeechRecognizer("127.0.0.1", "20002", "grpc")
+	failover := NewFailover(primary, grpcRecognizer, FailoverConfig{})
+
+	t.NoError(failover.Close())
+	t.True(primary.closed)
+	t.Equal(connectivity.Shutdown, grpcRecognizer.connection.GetState())
+}
+
+func (t *TestUnitFailoverSuite) TestCancelNotResetFailures() {
+	var primaryErr error
+	primary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, primaryErr
+	})
+	failover := NewFailover(primary, okRecognizer(), FailoverConfig{DemoteAfter: 2})
+
+	transportErr := &common.TransportError{Op: "language", Err: errors.New("connection refused")}
+	for _, err := range []error{transportErr, context.Canceled, transportErr} {
+		primaryErr = err
+		_, _ = failover.RecogniteLanguage(nil)
+	}
+	t.True(failover.Demoted())
+}
+
+func (t *TestUnitFailoverSuite) TestClientErrorNotFailover() {
+	primary := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, &common.APIError{StatusCode: common.APIStatusCodeClientErrorConfig}
+	})
+	secondary := okRecognizer()
+	failover := NewFailover(primary, secondary, FailoverConfig{DemoteAfter: 1})
+
+	_, err := failover.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+	t.Equal(0, secondary.calls)
+	t.False(failover.Demoted())
+}
+
+func (t *TestUnitFailoverSuite) TestTransportFailover() {
+	failover, err := NewTransportFailover("127.0.0.1", "20001", "20002", "grpc", FailoverConfig{})
+	t.NoError(err)
+	t.IsType(&GRPCSpeechRecognizer{}, failover.primary)
+	t.IsType(&HTTPSpeechRecognizer{}, failover.secondary)
+
+	failover, err = NewTransportFailover("127.0.0.1", "20001", "20002", "HTTP", FailoverConfig{})
+	t.NoError(err)
+	t.Equal("http", failover.primary.(*HTTPSpeechRecognizer).Protocol)
+	t.Equal("grpc", failover.secondary.(*GRPCSpeechRecognizer).Protocol)
+
+	failover, err = NewTransportFailover("127.0.0.1", "20001", "20002", "grpcs", FailoverConfig{})
+	t.NoError(err)
+	t.Equal("grpcs", failover.primary.(*GRPCSpeechRecognizer).Protocol)
+	t.Equal("https", failover.secondary.(*HTTPSpeechRecognizer).Protocol)
+
+	_, err = NewTransportFailover("127.0.0.1", "20001", "20002", "ftp", FailoverConfig{})
+	t.Error(err)
+
+	// 任一实例构造失败时返回错误，而不是保存一个nil实例
+	badTLS := WithTLS(TLSConfig{CAFile: "not-exist.pem"})
+	for _, primary := range []string{"grpc", "http", "grpcs"} {
+		failover, err = NewTransportFailover("127.0.0.1", "20001", "20002", primary, FailoverConfig{}, badTLS)
+		t.Error(err, primary)
+		t.Nil(failover)
+	}
+}
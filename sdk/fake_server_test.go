@@ -0,0 +1,142 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+	grpcClient "github.com/nl8590687/asrt-sdk-go/grpc"
+)
+
+// fakeGRPCServer 测试用的ASRT gRPC服务端，所有识别接口都返回固定的文本
+type fakeGRPCServer struct {
+	grpcClient.UnimplementedAsrtGrpcServiceServer
+	text string
+}
+
+func (s *fakeGRPCServer) All(ctx context.Context, request *grpcClient.SpeechRequest,
+) (*grpcClient.TextResponse, error) {
+	return &grpcClient.TextResponse{StatusCode: int32(common.APIStatusCodeOK), TextResult: s.text}, nil
+}
+
+func (s *fakeGRPCServer) Language(ctx context.Context, request *grpcClient.LanguageRequest,
+) (*grpcClient.TextResponse, error) {
+	return &grpcClient.TextResponse{StatusCode: int32(common.APIStatusCodeOK), TextResult: s.text}, nil
+}
+
+// Stream 对收到的每一段音频返回固定的文本
+func (s *fakeGRPCServer) Stream(stream grpcClient.AsrtGrpcService_StreamServer) error {
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		err = stream.Send(&grpcClient.TextResponse{StatusCode: int32(common.APIStatusCodeOK), TextResult: s.text})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// startFakeGRPCServer 在本机随机端口启动测试用gRPC服务端，register 可以注册额外的服务，
+// 返回监听端口，测试结束时自动停止
+func startFakeGRPCServer(t *testing.T, register func(server *grpc.Server), opts ...grpc.ServerOption) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(opts...)
+	grpcClient.RegisterAsrtGrpcServiceServer(server, &fakeGRPCServer{text: "你好"})
+	if register != nil {
+		register(server)
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+// fakeHTTPHandler 测试用的ASRT HTTP接口，所有接口都返回固定的识别文本
+func fakeHTTPHandler(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(common.AsrtAPIResponse{
+		StatusCode:    common.APIStatusCodeOK,
+		StatucMesaage: "ok",
+		Result:        "你好",
+	})
+}
+
+// startFakeHTTPServer 启动测试用HTTP服务端，handler 为nil时所有接口都返回固定的识别文本，
+// 返回服务端和监听端口，测试结束时自动停止
+func startFakeHTTPServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, string) {
+	if handler == nil {
+		handler = fakeHTTPHandler
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	return server, port
+}
+
+// fakeRecognizer 按 handler 返回结果的测试用识别器
+type fakeRecognizer struct {
+	recognizerMixin
+	handler func(ctx context.Context) (*common.AsrtAPIResponse, error)
+	mutex   sync.Mutex
+	calls   int
+}
+
+func newFakeRecognizer(handler func(ctx context.Context) (*common.AsrtAPIResponse, error)) *fakeRecognizer {
+	fake := &fakeRecognizer{handler: handler}
+	fake.recognizerMixin = recognizerMixin{self: fake}
+
+	return fake
+}
+
+func (f *fakeRecognizer) RecogniteContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	f.mutex.Lock()
+	f.calls += 1
+	f.mutex.Unlock()
+	return f.handler(ctx)
+}
+
+func (f *fakeRecognizer) RecogniteSpeechContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	f.mutex.Lock()
+	f.calls += 1
+	f.mutex.Unlock()
+	return f.handler(ctx)
+}
+
+func (f *fakeRecognizer) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	f.mutex.Lock()
+	f.calls += 1
+	f.mutex.Unlock()
+	return f.handler(ctx)
+}
+
+// okRecognizer 总是返回成功的测试用识别器
+func okRecognizer() *fakeRecognizer {
+	return newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+	})
+}
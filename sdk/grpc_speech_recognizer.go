@@ -2,12 +2,18 @@ package sdk
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"strings"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/nl8590687/asrt-sdk-go/common"
 	grpcClient "github.com/nl8590687/asrt-sdk-go/grpc"
@@ -20,32 +26,77 @@ type GRPCSpeechRecognizer struct {
 	connection *grpc.ClientConn
 }
 
-// NewGRPCSpeechRecognizer 构造一个用于调用grpc+pb协议接口的语音识别类实例对象
-func NewGRPCSpeechRecognizer(host string, port string, protocol string) *GRPCSpeechRecognizer {
-	protocol = strings.ToLower(protocol)
-	if protocol != "grpc" && protocol != "grpcs" {
-		return nil
-	}
-
+// NewGRPCSpeechRecognizer 构造一个用于调用grpc+pb协议接口的语音识别类实例对象，
+// 协议不支持、TLS配置加载失败或连接参数错误时返回nil，需要具体错误信息时请使用 NewSpeechRecognizerFromURL
+func NewGRPCSpeechRecognizer(host string, port string, protocol string, opts ...Option) *GRPCSpeechRecognizer {
 	base := BaseSpeechRecognizer{
 		Host:     host,
 		Port:     port,
-		Protocol: protocol,
+		Protocol: strings.ToLower(protocol),
+		options:  newOptions(opts...),
+	}
+	grpcSpeechRecognizer, err := newGRPCSpeechRecognizer(base, fmt.Sprintf("%s:%s", host, port))
+	if err != nil {
+		base.logger().Error("create grpc speech recognizer failed", common.F("error", err))
+		return nil
 	}
 
-	address := fmt.Sprintf("%s:%s", host, port)
-	// 得到 gRPC 链接客户端句柄
-	var conn *grpc.ClientConn
-	var err error
-	if protocol == "grpc" {
-		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	} else {
-		conn, err = grpc.Dial(address)
+	return grpcSpeechRecognizer
+}
+
+// newGRPCSpeechRecognizer 根据已经解析好的配置构造gRPC协议的语音识别类实例对象，
+// address 为gRPC的目标地址，例如 "host:port" 或 "unix:///run/asrt.sock"
+func newGRPCSpeechRecognizer(base BaseSpeechRecognizer, address string) (*GRPCSpeechRecognizer, error) {
+	if base.Protocol != "grpc" && base.Protocol != "grpcs" {
+		return nil, fmt.Errorf("error: unsupported grpc protocol `%s`", base.Protocol)
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if base.Protocol == "grpcs" {
+		tlsConfig, err := base.options.clientTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		dialOptions[0] = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	if base.options.hasAuth() {
+		// Unix域套接字只能在本机访问，允许不使用TLS携带认证信息
+		allowInsecure := base.options.insecureAuth || strings.HasPrefix(address, "unix://")
+		if base.Protocol != "grpcs" && !allowInsecure {
+			return nil, fmt.Errorf("error: refuse to send credentials over insecure grpc, " +
+				"use grpcs or allow it explicitly with WithInsecureAuth")
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(
+			perRPCAuth{options: base.options, allowInsecure: allowInsecure}))
+	}
+	for _, build := range []func() (grpc.DialOption, error){
+		base.options.grpcKeepaliveOption, base.options.grpcCompressionOption,
+	} {
+		option, err := build()
+		if err != nil {
+			return nil, err
+		}
+		if option != nil {
+			dialOptions = append(dialOptions, option)
+		}
+	}
+	if !strings.HasPrefix(address, "unix://") {
+		dialer, err := base.options.grpcProxyDialer()
+		if err != nil {
+			return nil, err
+		}
+		if dialer != nil {
+			dialOptions = append(dialOptions, grpc.WithContextDialer(dialer))
+		}
 	}
 
+	// 得到 gRPC 链接客户端句柄
+	conn, err := grpc.Dial(address, dialOptions...)
 	if err != nil {
-		log.Printf("error: did not connect to `%s`, %s", address, err)
-		return nil
+		return nil, fmt.Errorf("error: grpc dial `%s` failed, %w", address, err)
 	}
 
 	grpcSpeechRecognizer := GRPCSpeechRecognizer{
@@ -55,16 +106,23 @@ func NewGRPCSpeechRecognizer(host string, port string, protocol string) *GRPCSpe
 		connection: conn,
 	}
 
-	return &grpcSpeechRecognizer
+	return &grpcSpeechRecognizer, nil
 }
 
 // Recognite 调用ASRT语音识别
+//
+// 错误类型与 HTTPSpeechRecognizer.Recognite 一致，gRPC状态码会被转换为对应分类的错误
 func (g *GRPCSpeechRecognizer) Recognite(wavData []byte, frameRate int, channels int, byteWidth int,
 ) (*common.AsrtAPIResponse, error) {
-	if len(wavData) > wavDataMaxLength {
-		return nil, fmt.Errorf("error: %s `%d`, %s `%d`",
-			"Too long wave sample byte length:", len(wavData),
-			"the max length is", wavDataMaxLength)
+	return g.RecogniteContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteContext 调用ASRT语音识别，可以通过 ctx 控制超时和取消
+func (g *GRPCSpeechRecognizer) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := checkWavDataLength(wavData); err != nil {
+		return nil, err
 	}
 	grpcRequest := grpcClient.SpeechRequest{
 		WavData: &grpcClient.WavData{
@@ -75,27 +133,38 @@ func (g *GRPCSpeechRecognizer) Recognite(wavData []byte, frameRate int, channels
 		},
 	}
 
-	grpcResponse, err := g.Client.All(context.Background(), &grpcRequest, grpc.EmptyCallOption{})
-	if err != nil {
-		return nil, err
-	}
+	return g.invoke(ctx, "all", func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		var header, trailer metadata.MD
+		grpcResponse, err := g.Client.All(g.outgoingContext(ctx), &grpcRequest,
+			grpc.Header(&header), grpc.Trailer(&trailer))
+		if err != nil {
+			return nil, g.translateError("all", err, header, trailer)
+		}
 
-	apiResponse := common.AsrtAPIResponse{
-		StatusCode:    int(grpcResponse.StatusCode),
-		StatucMesaage: grpcResponse.StatusMessage,
-		Result:        grpcResponse.TextResult,
-	}
+		apiResponse := common.AsrtAPIResponse{
+			StatusCode:    int(grpcResponse.StatusCode),
+			StatucMesaage: grpcResponse.StatusMessage,
+			Result:        grpcResponse.TextResult,
+		}
 
-	return &apiResponse, nil
+		g.readResponseMetadata(&apiResponse, header, trailer)
+
+		return checkAPIResponse(&apiResponse)
+	})
 }
 
 // RecogniteSpeech 调用ASRT语音识别声学模型
 func (g *GRPCSpeechRecognizer) RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int,
 ) (*common.AsrtAPIResponse, error) {
-	if len(wavData) > wavDataMaxLength {
-		return nil, fmt.Errorf("error: %s `%d`, %s `%d`",
-			"Too long wave sample byte length:", len(wavData),
-			"the max length is", wavDataMaxLength)
+	return g.RecogniteSpeechContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteSpeechContext 调用ASRT语音识别声学模型，可以通过 ctx 控制超时和取消
+func (g *GRPCSpeechRecognizer) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := checkWavDataLength(wavData); err != nil {
+		return nil, err
 	}
 	grpcRequest := grpcClient.SpeechRequest{
 		WavData: &grpcClient.WavData{
@@ -106,74 +175,119 @@ func (g *GRPCSpeechRecognizer) RecogniteSpeech(wavData []byte, frameRate int, ch
 		},
 	}
 
-	grpcResponse, err := g.Client.Speech(context.Background(), &grpcRequest, grpc.EmptyCallOption{})
-	if err != nil {
-		return nil, err
-	}
+	return g.invoke(ctx, "speech", func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		var header, trailer metadata.MD
+		grpcResponse, err := g.Client.Speech(g.outgoingContext(ctx), &grpcRequest,
+			grpc.Header(&header), grpc.Trailer(&trailer))
+		if err != nil {
+			return nil, g.translateError("speech", err, header, trailer)
+		}
 
-	apiResponse := common.AsrtAPIResponse{
-		StatusCode:    int(grpcResponse.StatusCode),
-		StatucMesaage: grpcResponse.StatusMessage,
-		Result:        grpcResponse.ResultData,
-	}
+		apiResponse := common.AsrtAPIResponse{
+			StatusCode:    int(grpcResponse.StatusCode),
+			StatucMesaage: grpcResponse.StatusMessage,
+			Result:        grpcResponse.ResultData,
+		}
 
-	return &apiResponse, nil
+		g.readResponseMetadata(&apiResponse, header, trailer)
+
+		return checkAPIResponse(&apiResponse)
+	})
 }
 
 // RecogniteLanguage 调用ASRT语音识别语言模型
 func (g *GRPCSpeechRecognizer) RecogniteLanguage(sequencePinyin []string) (*common.AsrtAPIResponse, error) {
+	return g.RecogniteLanguageContext(context.Background(), sequencePinyin)
+}
+
+// RecogniteLanguageContext 调用ASRT语音识别语言模型，可以通过 ctx 控制超时和取消
+func (g *GRPCSpeechRecognizer) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
 	grpcRequest := grpcClient.LanguageRequest{
 		Pinyins: sequencePinyin,
 	}
 
-	grpcResponse, err := g.Client.Language(context.Background(), &grpcRequest, grpc.EmptyCallOption{})
-	if err != nil {
-		return nil, err
-	}
+	return g.invoke(ctx, "language", func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		var header, trailer metadata.MD
+		grpcResponse, err := g.Client.Language(g.outgoingContext(ctx), &grpcRequest,
+			grpc.Header(&header), grpc.Trailer(&trailer))
+		if err != nil {
+			return nil, g.translateError("language", err, header, trailer)
+		}
 
-	apiResponse := common.AsrtAPIResponse{
-		StatusCode:    int(grpcResponse.StatusCode),
-		StatucMesaage: grpcResponse.StatusMessage,
-		Result:        grpcResponse.TextResult,
-	}
+		apiResponse := common.AsrtAPIResponse{
+			StatusCode:    int(grpcResponse.StatusCode),
+			StatucMesaage: grpcResponse.StatusMessage,
+			Result:        grpcResponse.TextResult,
+		}
+
+		g.readResponseMetadata(&apiResponse, header, trailer)
 
-	return &apiResponse, nil
+		return checkAPIResponse(&apiResponse)
+	})
 }
 
 // RecogniteStream 调用ASRT语音识别来流式识别音频
+//
+// 识别结果依次写入 resultChannel，调用方需要持续读取直到其被关闭。
+// resultChannel 总是由本方法关闭，调用方不应再关闭它；方法返回时全部识别结果都已写入。
+// 流式识别不使用 WithTimeout 设置的超时时间和 WithRetryPolicy 设置的重试策略
 func (g *GRPCSpeechRecognizer) RecogniteStream(wavChannel <-chan *common.Wav,
 	resultChannel chan<- *common.AsrtAPIResponse,
 ) error {
-	ctx := context.Background()
-	streamClient, err := g.Client.Stream(ctx, grpc.EmptyCallOption{})
+	ctx, requestID := ensureRequestID(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streamClient, err := g.Client.Stream(g.outgoingContext(ctx))
 	if err != nil {
-		return fmt.Errorf("error:%s", err.Error())
+		close(resultChannel)
+		return translateGRPCError("stream", err)
 	}
 
-	recvFunction := func(ctx context.Context) {
+	recvDone := make(chan error, 1)
+	go func() {
+		defer close(resultChannel)
 		for {
-			select {
-			case <-ctx.Done():
+			grpcResponse, err := streamClient.Recv()
+			if err == io.EOF {
+				g.logger().Debug("stream asr result finished")
+				recvDone <- nil
 				return
-			default:
-				grpcResponse, err := streamClient.Recv()
-				if err != nil {
-					log.Println("error: the function to recv stream asr result is error,", err.Error())
-					close(resultChannel)
-					return
-				}
-
-				apiResponse := &common.AsrtAPIResponse{
-					StatusCode:    int(grpcResponse.StatusCode),
-					StatucMesaage: grpcResponse.StatusMessage,
-					Result:        grpcResponse.TextResult,
-				}
-				resultChannel <- apiResponse
+			} else if err != nil {
+				g.logger().Error("recv stream asr result failed", common.F("error", err))
+				recvDone <- err
+				return
+			}
+
+			resultChannel <- &common.AsrtAPIResponse{
+				StatusCode:    int(grpcResponse.StatusCode),
+				StatucMesaage: grpcResponse.StatusMessage,
+				Result:        grpcResponse.TextResult,
+				RequestID:     requestID,
 			}
 		}
+	}()
+
+	sendErr := sendStream(streamClient, wavChannel)
+	if sendErr != nil {
+		// 发送失败时取消流，使接收协程尽快结束
+		cancel()
 	}
-	go recvFunction(ctx)
+	recvErr := <-recvDone
+	if sendErr != nil {
+		return translateGRPCError("stream", sendErr)
+	}
+	if recvErr != nil {
+		return translateGRPCError("stream", recvErr)
+	}
+
+	return nil
+}
 
+// sendStream 将 wavChannel 中的音频依次发送到流中，发送完毕后关闭发送方向，
+// 服务端提前结束流时停止发送，具体原因由接收方向返回
+func sendStream(streamClient grpcClient.AsrtGrpcService_StreamClient, wavChannel <-chan *common.Wav) error {
 	for value := range wavChannel {
 		grpcRequest := grpcClient.SpeechRequest{
 			WavData: &grpcClient.WavData{
@@ -183,68 +297,123 @@ func (g *GRPCSpeechRecognizer) RecogniteStream(wavChannel <-chan *common.Wav,
 				ByteWidth:  int32(value.SampleWidth),
 			},
 		}
-		err = streamClient.Send(&grpcRequest)
-		if err != nil {
-			return fmt.Errorf("error:%s", err.Error())
+		if err := streamClient.Send(&grpcRequest); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
 		}
 	}
 
-	err = streamClient.CloseSend()
-	if err != nil {
-		return fmt.Errorf("error:%s", err.Error())
-	}
-
-	_, cancel := context.WithCancel(ctx)
-	cancel()
-
-	return nil
+	return streamClient.CloseSend()
 }
 
 // RecogniteLong 调用ASRT语音识别来识别长音频序列
 func (g *GRPCSpeechRecognizer) RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int,
 ) ([]*common.AsrtAPIResponse, error) {
-	if frameRate != 16000 {
-		return nil, fmt.Errorf("error: unsupport wave sample rate `%d`", frameRate)
+	return g.RecogniteLongContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLongContext 调用ASRT语音识别来识别长音频序列，可以通过 ctx 控制超时和取消
+func (g *GRPCSpeechRecognizer) RecogniteLongContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteLong(ctx, g.RecogniteContext, wavData, frameRate, channels, byteWidth, opts...)
+}
+
+// RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
+func (g *GRPCSpeechRecognizer) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
+	return g.RecogniteFileContext(context.Background(), filename)
+}
+
+// RecogniteFileContext 调用ASRT语音识别来识别指定文件名的音频文件，可以通过 ctx 控制超时和取消
+func (g *GRPCSpeechRecognizer) RecogniteFileContext(ctx context.Context, filename string,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteFile(ctx, g.RecogniteContext, filename, opts...)
+}
+
+// translateGRPCError 将gRPC调用错误转换为对应分类的SDK错误：
+// 参数和配置类错误转换为客户端错误，服务端内部错误转换为 *common.APIError，
+// 连接不可用、超时、取消等转换为 *common.TransportError
+func translateGRPCError(op string, err error) error {
+	var authErr *authError
+	if errors.As(err, &authErr) {
+		// 获取认证信息失败时请求未发送到服务端，与HTTP协议一样返回 common.ErrAuth 分类的错误
+		return authErr.err
 	}
-	if channels != 1 {
-		return nil, fmt.Errorf("error: unsupport wave channels number `%d`", channels)
+	st, ok := status.FromError(err)
+	if !ok {
+		return &common.TransportError{Op: op, Err: err}
 	}
-	if byteWidth != 2 {
-		return nil, fmt.Errorf("error: unsupport wave byte width `%d`", byteWidth)
+
+	statusCode := 0
+	switch st.Code() {
+	case codes.InvalidArgument:
+		statusCode = common.APIStatusCodeClientErrorFormat
+	case codes.Unimplemented, codes.FailedPrecondition, codes.OutOfRange:
+		statusCode = common.APIStatusCodeClientErrorConfig
+	case codes.Unauthenticated, codes.PermissionDenied, codes.NotFound, codes.AlreadyExists:
+		statusCode = common.APIStatusCodeClientError
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		statusCode = common.APIStatusCodeServerError
+	default:
+		// Unavailable、DeadlineExceeded、Canceled、ResourceExhausted、Aborted 等
+		return &common.TransportError{Op: op, Err: err}
 	}
 
-	byteData := wavData
-	var asrtResult []*common.AsrtAPIResponse
-	duration := 2 * 16000 * 10
+	return &common.APIError{
+		StatusCode: statusCode,
+		Message:    st.Message(),
+		GRPCCode:   st.Code(),
+	}
+}
 
-	index := 0
-	for ; index < len(byteData)/duration+1; index++ {
-		rsp, err := g.Recognite(byteData, frameRate, channels, byteWidth)
-		if err != nil {
-			return asrtResult, err
-		}
+// Close 关闭gRPC连接
+func (g *GRPCSpeechRecognizer) Close() {
+	g.connection.Close()
+}
 
-		asrtResult = append(asrtResult, rsp)
+// outgoingContext 将请求ID和用户元数据添加到gRPC请求的元数据中
+func (g *GRPCSpeechRecognizer) outgoingContext(ctx context.Context) context.Context {
+	md := g.options.requestMetadata(ctx)
+	if len(md) == 0 {
+		return ctx
 	}
 
-	return asrtResult, nil
+	pairs := make([]string, 0, 2*len(md))
+	for key, value := range md {
+		pairs = append(pairs, strings.ToLower(key), value)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
 }
 
-// RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
-func (g *GRPCSpeechRecognizer) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
-	binData := common.ReadBinFile(filename)
-	wavAudio := common.Wav{}
-	err := wavAudio.Deserialize(binData)
-	if err != nil {
-		return nil, err
+// readResponseMetadata 从gRPC响应头和尾部元数据中读取服务端回传的请求ID和 server-timing 耗时信息
+func (g *GRPCSpeechRecognizer) readResponseMetadata(response *common.AsrtAPIResponse, mds ...metadata.MD) {
+	response.RequestID = g.echoedRequestID(mds...)
+	for _, md := range mds {
+		response.ServerTiming = append(response.ServerTiming, common.ParseServerTiming(md.Get("server-timing")...)...)
 	}
+}
 
-	asrtResult, err := g.RecogniteLong(wavAudio.GetRawSamples(),
-		wavAudio.FrameRate, wavAudio.Channels, wavAudio.SampleWidth)
+// echoedRequestID 获取服务端在响应元数据中回传的请求ID，没有回传时返回空字符串
+func (g *GRPCSpeechRecognizer) echoedRequestID(mds ...metadata.MD) string {
+	key := strings.ToLower(g.options.requestIDHeaderName())
+	for _, md := range mds {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
 
-	return asrtResult, err
+	return ""
 }
 
-func (g *GRPCSpeechRecognizer) Close() {
-	g.connection.Close()
+// translateError 转换gRPC请求返回的错误，并补充服务端随错误一起回传的请求ID
+func (g *GRPCSpeechRecognizer) translateError(op string, err error, mds ...metadata.MD) error {
+	err = translateGRPCError(op, err)
+	if requestID := g.echoedRequestID(mds...); requestID != "" {
+		tagRequestID(nil, err, requestID)
+	}
+
+	return err
 }
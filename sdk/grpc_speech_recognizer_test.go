@@ -0,0 +1,88 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitGRPCSpeechRecognizer(t *testing.T) {
+	suite.Run(t, new(TestUnitGRPCSpeechRecognizerSuite))
+}
+
+type TestUnitGRPCSpeechRecognizerSuite struct {
+	suite.Suite
+}
+
+// stream 发送 count 段音频进行流式识别，返回收到的全部结果和识别返回的错误
+func (t *TestUnitGRPCSpeechRecognizerSuite) stream(recognizer *GRPCSpeechRecognizer, count int,
+) ([]*common.AsrtAPIResponse, error) {
+	wavChannel := make(chan *common.Wav, count)
+	for i := 0; i < count; i += 1 {
+		wave := common.GenerateSilence(100*time.Millisecond, 16000, 1)
+		wavChannel <- &wave
+	}
+	close(wavChannel)
+
+	resultChannel := make(chan *common.AsrtAPIResponse)
+	var results []*common.AsrtAPIResponse
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range resultChannel {
+			results = append(results, result)
+		}
+	}()
+
+	err := recognizer.RecogniteStream(wavChannel, resultChannel)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fail("result channel is not closed")
+	}
+
+	return results, err
+}
+
+func (t *TestUnitGRPCSpeechRecognizerSuite) TestStream() {
+	port := startFakeGRPCServer(t.T(), nil)
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+
+	// 返回时全部结果都已写入，并且 resultChannel 已经由识别方法关闭
+	results, err := t.stream(recognizer, 3)
+	t.NoError(err)
+	t.Len(results, 3)
+	for _, result := range results {
+		t.Equal("你好", result.Result)
+		t.Equal(results[0].RequestID, result.RequestID)
+	}
+	t.NotEmpty(results[0].RequestID)
+}
+
+func (t *TestUnitGRPCSpeechRecognizerSuite) TestStreamServerError() {
+	interceptor := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return status.Error(codes.InvalidArgument, "bad stream")
+	}
+	port := startFakeGRPCServer(t.T(), nil, grpc.StreamInterceptor(interceptor))
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+
+	results, err := t.stream(recognizer, 3)
+	t.ErrorIs(err, common.ErrClientFormat)
+	t.Empty(results)
+
+	// 连接失败时同样关闭 resultChannel
+	closed := NewGRPCSpeechRecognizer("127.0.0.1", "1", "grpc")
+	defer closed.Close()
+	_, err = t.stream(closed, 1)
+	t.ErrorIs(err, common.ErrTransport)
+}
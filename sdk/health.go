@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// HealthMethodGRPC 使用标准gRPC健康检查协议 grpc.health.v1 完成的检查
+	HealthMethodGRPC = "grpc.health.v1"
+	// HealthMethodRoundTrip 使用一次很小的语言模型识别请求完成的检查
+	HealthMethodRoundTrip = "round-trip"
+
+	// defaultProbeEvery 后台健康检查的默认间隔
+	defaultProbeEvery = 10 * time.Second
+	// defaultProbeTimeout 后台健康检查单次检查的默认超时时间
+	defaultProbeTimeout = 5 * time.Second
+)
+
+// healthCheckPinyin 健康检查时发送给语言模型的拼音序列
+var healthCheckPinyin = []string{"ni3", "hao3"}
+
+// HealthStatus 一次健康检查的结果
+type HealthStatus struct {
+	// Ready 服务端是否可以正常处理识别请求
+	Ready bool
+	// Latency 健康检查耗时
+	Latency time.Duration
+	// Method 健康检查使用的方式，为 HealthMethodGRPC 或 HealthMethodRoundTrip
+	Method string
+	// CheckedAt 健康检查开始的时间
+	CheckedAt time.Time
+	// Err 服务端未就绪的原因
+	Err error
+}
+
+// HealthChecker 支持健康检查的语音识别实例
+type HealthChecker interface {
+	// HealthCheck 检查服务端是否存活并可以正常处理识别请求
+	HealthCheck(ctx context.Context) HealthStatus
+}
+
+// HealthCheck 检查服务端是否存活并可以正常处理识别请求，
+// 通过一次很小的语言模型识别请求完成，健康检查不会按重试策略重试
+func (h *HTTPSpeechRecognizer) HealthCheck(ctx context.Context) HealthStatus {
+	return healthRoundTrip(ctx, h.RecogniteLanguageContext)
+}
+
+// Ping 检查服务端是否可用，返回健康检查耗时，参见 HealthCheck
+func (h *HTTPSpeechRecognizer) Ping(ctx context.Context) (time.Duration, error) {
+	result := h.HealthCheck(ctx)
+	return result.Latency, result.Err
+}
+
+// HealthCheck 检查服务端是否存活并可以正常处理识别请求，
+// 服务端支持标准gRPC健康检查协议时使用该协议，否则通过一次很小的语言模型识别请求完成，
+// 健康检查不会按重试策略重试
+func (g *GRPCSpeechRecognizer) HealthCheck(ctx context.Context) HealthStatus {
+	checkedAt := time.Now()
+	client := grpc_health_v1.NewHealthClient(g.connection)
+	rsp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		g.logger().Debug("grpc health service unimplemented, fallback to round trip")
+		return healthRoundTrip(ctx, g.RecogniteLanguageContext)
+	}
+
+	result := HealthStatus{
+		Latency:   time.Since(checkedAt),
+		Method:    HealthMethodGRPC,
+		CheckedAt: checkedAt,
+	}
+	if err != nil {
+		result.Err = translateGRPCError("health", err)
+	} else if rsp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		result.Err = &common.APIError{
+			StatusCode: common.APIStatusCodeServerError,
+			Message:    fmt.Sprintf("grpc health status %s", rsp.Status),
+		}
+	}
+	result.Ready = result.Err == nil
+
+	return result
+}
+
+// Ping 检查服务端是否可用，返回健康检查耗时，参见 HealthCheck
+func (g *GRPCSpeechRecognizer) Ping(ctx context.Context) (time.Duration, error) {
+	result := g.HealthCheck(ctx)
+	return result.Latency, result.Err
+}
+
+// healthRoundTrip 通过一次语言模型识别请求检查服务端，只得到部分识别结果也视为就绪
+func healthRoundTrip(ctx context.Context,
+	language func(ctx context.Context, sequencePinyin []string) (*common.AsrtAPIResponse, error),
+) HealthStatus {
+	checkedAt := time.Now()
+	_, err := language(withoutRetry(ctx), healthCheckPinyin)
+	if errors.Is(err, common.ErrPartialResult) {
+		err = nil
+	}
+
+	return HealthStatus{
+		Ready:     err == nil,
+		Latency:   time.Since(checkedAt),
+		Method:    HealthMethodRoundTrip,
+		CheckedAt: checkedAt,
+		Err:       err,
+	}
+}
+
+// HealthProberConfig 后台健康检查配置，零值表示使用默认值
+type HealthProberConfig struct {
+	// Interval 健康检查的间隔，默认10s
+	Interval time.Duration
+	// Timeout 单次健康检查的超时时间，默认5s
+	Timeout time.Duration
+	// OnChange 就绪状态变化时的回调，第一次检查完成时也会回调，为nil时不回调
+	OnChange func(result HealthStatus)
+}
+
+// HealthProber 定期在后台执行健康检查
+type HealthProber struct {
+	checker HealthChecker
+	config  HealthProberConfig
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mutex  sync.Mutex
+	last   HealthStatus
+	probed bool
+}
+
+// StartHealthProber 启动后台健康检查，立即执行第一次检查，此后按 Interval 定期检查，
+// ctx 被取消或调用 Stop 后停止
+func StartHealthProber(ctx context.Context, checker HealthChecker, config HealthProberConfig) *HealthProber {
+	if config.Interval <= 0 {
+		config.Interval = defaultProbeEvery
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultProbeTimeout
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	prober := &HealthProber{
+		checker: checker,
+		config:  config,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go prober.run(ctx)
+
+	return prober
+}
+
+// Status 获取最近一次健康检查的结果，第一次检查完成前 ok 为false
+func (p *HealthProber) Status() (result HealthStatus, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.last, p.probed
+}
+
+// Stop 停止后台健康检查，并等待正在进行的检查结束
+func (p *HealthProber) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// run 后台健康检查循环
+func (p *HealthProber) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		p.probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe 执行一次健康检查并记录结果
+func (p *HealthProber) probe(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	result := p.checker.HealthCheck(checkCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mutex.Lock()
+	changed := !p.probed || p.last.Ready != result.Ready
+	p.last = result
+	p.probed = true
+	p.mutex.Unlock()
+
+	if changed && p.config.OnChange != nil {
+		p.config.OnChange(result)
+	}
+}
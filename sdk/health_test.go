@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitHealth(t *testing.T) {
+	suite.Run(t, new(TestUnitHealthSuite))
+}
+
+type TestUnitHealthSuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitHealthSuite) TestHTTPHealthCheck() {
+	_, port := startFakeHTTPServer(t.T(), nil)
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+	result := recognizer.HealthCheck(context.Background())
+	t.True(result.Ready)
+	t.NoError(result.Err)
+	t.Equal(HealthMethodRoundTrip, result.Method)
+	t.True(result.Latency > 0)
+
+	calls := 0
+	_, port = startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		calls += 1
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithRetryPolicy(DefaultRetryPolicy()))
+	_, err := recognizer.Ping(context.Background())
+	t.ErrorIs(err, common.ErrServer)
+	t.Equal(1, calls)
+}
+
+func (t *TestUnitHealthSuite) TestGRPCHealthProtocol() {
+	healthServer := health.NewServer()
+	port := startFakeGRPCServer(t.T(), func(server *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(server, healthServer)
+	})
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+
+	result := recognizer.HealthCheck(context.Background())
+	t.True(result.Ready)
+	t.Equal(HealthMethodGRPC, result.Method)
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	result = recognizer.HealthCheck(context.Background())
+	t.False(result.Ready)
+	t.ErrorIs(result.Err, common.ErrServer)
+}
+
+func (t *TestUnitHealthSuite) TestGRPCRoundTripFallback() {
+	port := startFakeGRPCServer(t.T(), nil)
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+
+	latency, err := recognizer.Ping(context.Background())
+	t.NoError(err)
+	t.True(latency > 0)
+	t.Equal(HealthMethodRoundTrip, recognizer.HealthCheck(context.Background()).Method)
+}
+
+// fakeHealthChecker 按 ready 返回结果的测试用健康检查实现
+type fakeHealthChecker struct {
+	mutex sync.Mutex
+	ready bool
+}
+
+func (c *fakeHealthChecker) HealthCheck(ctx context.Context) HealthStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return HealthStatus{Ready: c.ready, CheckedAt: time.Now()}
+}
+
+func (t *TestUnitHealthSuite) TestProber() {
+	checker := &fakeHealthChecker{ready: true}
+	changes := make(chan HealthStatus, 10)
+	prober := StartHealthProber(context.Background(), checker, HealthProberConfig{
+		Interval: 5 * time.Millisecond,
+		OnChange: func(result HealthStatus) {
+			changes <- result
+		},
+	})
+	defer prober.Stop()
+
+	t.True((<-changes).Ready)
+	checker.mutex.Lock()
+	checker.ready = false
+	checker.mutex.Unlock()
+	t.False((<-changes).Ready)
+
+	result, ok := prober.Status()
+	t.True(ok)
+	t.False(result.Ready)
+}
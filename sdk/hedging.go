@@ -0,0 +1,277 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// defaultHedgePercentile 默认按已完成请求耗时的P95决定对冲等待时间
+	defaultHedgePercentile = 0.95
+	// defaultHedgeInitialDelay 耗时样本不足时默认的对冲等待时间
+	defaultHedgeInitialDelay = time.Second
+	// defaultHedgeMinDelay 对冲等待时间的默认下限
+	defaultHedgeMinDelay = 10 * time.Millisecond
+	// defaultHedgeExtraLoad 对冲请求数占普通请求数比例的默认上限
+	defaultHedgeExtraLoad = 0.1
+	// defaultHedgeSamples 默认保留的耗时样本数
+	defaultHedgeSamples = 1000
+	// hedgeMinSamples 按百分位计算对冲等待时间所需的最少样本数
+	hedgeMinSamples = 20
+	// hedgeRecalcEvery 每新增多少个样本重新计算一次对冲等待时间
+	hedgeRecalcEvery = 16
+	// hedgeBurst 对冲预算允许累积的最大请求数
+	hedgeBurst = 10
+)
+
+// HedgingConfig 对冲请求配置，零值表示使用默认值
+type HedgingConfig struct {
+	// Percentile 对冲等待时间取最近成功请求耗时的该百分位，取值范围 (0, 1)，默认0.95
+	Percentile float64
+	// InitialDelay 耗时样本不足时使用的对冲等待时间，默认1s
+	InitialDelay time.Duration
+	// MinDelay 对冲等待时间的下限，默认10ms
+	MinDelay time.Duration
+	// MaxHedges 每次调用最多额外发送的对冲请求数，默认1
+	MaxHedges int
+	// MaxExtraLoad 对冲请求数占普通请求数比例的上限，例如0.1表示对冲最多增加10%的请求量，默认0.1
+	MaxExtraLoad float64
+	// Samples 计算百分位时保留的最近成功请求耗时样本数，默认1000
+	Samples int
+}
+
+// HedgingStats 对冲请求的统计信息
+type HedgingStats struct {
+	// Requests 调用次数
+	Requests uint64
+	// Hedges 发送的对冲请求数
+	Hedges uint64
+	// HedgeWins 对冲请求先于原请求成功返回的次数
+	HedgeWins uint64
+	// Delay 当前的对冲等待时间
+	Delay time.Duration
+}
+
+// Hedger 通过对冲请求降低长尾延迟的语音识别实例
+//
+// 每次调用先向一个后端发送请求，若超过对冲等待时间仍未返回，再向下一个后端发送相同的请求，
+// 采用最先成功返回的结果并取消其余请求。对冲请求的总量受 MaxExtraLoad 限制
+type Hedger struct {
+	recognizerMixin
+
+	backends []ContextSpeechRecognizer
+	config   HedgingConfig
+
+	mutex     sync.Mutex
+	next      int
+	samples   []time.Duration
+	sampled   int
+	delay     time.Duration
+	budget    float64
+	requests  uint64
+	hedges    uint64
+	hedgeWins uint64
+}
+
+// hedgeResult 单个请求的结果
+type hedgeResult struct {
+	index   int
+	rsp     *common.AsrtAPIResponse
+	err     error
+	latency time.Duration
+}
+
+// NewHedger 构造一个在 backends 之间发送对冲请求的语音识别实例，
+// 只有一个后端时对冲请求发往同一个后端
+func NewHedger(backends []ISpeechRecognizer, config HedgingConfig) (*Hedger, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("error: no backend for hedger")
+	}
+	contextBackends := make([]ContextSpeechRecognizer, 0, len(backends))
+	for i, backend := range backends {
+		if backend == nil {
+			return nil, fmt.Errorf("error: backend %d is nil", i)
+		}
+		contextBackends = append(contextBackends, withContext(backend))
+	}
+	if config.Percentile <= 0 || config.Percentile >= 1 {
+		config.Percentile = defaultHedgePercentile
+	}
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = defaultHedgeInitialDelay
+	}
+	if config.MinDelay <= 0 {
+		config.MinDelay = defaultHedgeMinDelay
+	}
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	if config.MaxExtraLoad <= 0 {
+		config.MaxExtraLoad = defaultHedgeExtraLoad
+	}
+	if config.Samples <= 0 {
+		config.Samples = defaultHedgeSamples
+	}
+
+	hedger := &Hedger{
+		backends: contextBackends,
+		config:   config,
+		samples:  make([]time.Duration, 0, config.Samples),
+		delay:    config.InitialDelay,
+		budget:   1,
+	}
+	hedger.recognizerMixin = recognizerMixin{self: hedger}
+
+	return hedger, nil
+}
+
+// Stats 获取对冲请求的统计信息
+func (h *Hedger) Stats() HedgingStats {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return HedgingStats{
+		Requests:  h.requests,
+		Hedges:    h.hedges,
+		HedgeWins: h.hedgeWins,
+		Delay:     h.delay,
+	}
+}
+
+// RecogniteContext 以对冲请求的方式调用ASRT语音识别
+func (h *Hedger) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return h.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteSpeechContext 以对冲请求的方式调用ASRT语音识别声学模型
+func (h *Hedger) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return h.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+	})
+}
+
+// RecogniteLanguageContext 以对冲请求的方式调用ASRT语音识别语言模型
+func (h *Hedger) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return h.call(ctx, func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error) {
+		return r.RecogniteLanguageContext(ctx, sequencePinyin)
+	})
+}
+
+// call 发送原请求，并在超过对冲等待时间后按预算发送对冲请求
+func (h *Hedger) call(ctx context.Context,
+	fn func(ctx context.Context, r ContextSpeechRecognizer) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	first, delay := h.begin()
+
+	ctx, cancel := context.WithCancel(ctx)
+	// 返回时取消其余仍在进行的请求
+	defer cancel()
+
+	results := make(chan hedgeResult, 1+h.config.MaxHedges)
+	launch := func(index int) {
+		backend := h.backends[(first+index)%len(h.backends)]
+		go func() {
+			start := time.Now()
+			rsp, err := fn(ctx, backend)
+			results <- hedgeResult{index: index, rsp: rsp, err: err, latency: time.Since(start)}
+		}()
+	}
+
+	launch(0)
+	launched, inFlight := 1, 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case result := <-results:
+			inFlight -= 1
+			// 传输错误和服务端错误可能只是该后端的问题，其余请求仍有机会成功
+			if result.err != nil && isBreakerFailure(result.err) && inFlight > 0 {
+				continue
+			}
+			h.finish(result)
+			return result.rsp, result.err
+		case <-timer.C:
+			if launched <= h.config.MaxHedges && h.takeBudget() {
+				launch(launched)
+				launched += 1
+				inFlight += 1
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// begin 开始一次调用，选择原请求的后端并获取当前的对冲等待时间
+func (h *Hedger) begin() (int, time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	first := h.next
+	h.next = (h.next + 1) % len(h.backends)
+	h.requests += 1
+	h.budget = math.Min(h.budget+h.config.MaxExtraLoad, hedgeBurst)
+
+	return first, h.delay
+}
+
+// takeBudget 从对冲预算中扣除一次对冲请求，预算不足时返回false
+func (h *Hedger) takeBudget() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.budget < 1 {
+		return false
+	}
+	h.budget -= 1
+	h.hedges += 1
+
+	return true
+}
+
+// finish 记录最终采用的结果，成功时将其耗时加入样本并按需重新计算对冲等待时间
+func (h *Hedger) finish(result hedgeResult) {
+	if result.err != nil && !errors.Is(result.err, common.ErrPartialResult) {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if result.index > 0 {
+		h.hedgeWins += 1
+	}
+
+	if len(h.samples) < h.config.Samples {
+		h.samples = append(h.samples, result.latency)
+	} else {
+		h.samples[h.sampled%h.config.Samples] = result.latency
+	}
+	h.sampled += 1
+
+	if len(h.samples) >= hedgeMinSamples && h.sampled%hedgeRecalcEvery == 0 {
+		sorted := append([]time.Duration(nil), h.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		h.delay = sorted[int(float64(len(sorted)-1)*h.config.Percentile)]
+		if h.delay < h.config.MinDelay {
+			h.delay = h.config.MinDelay
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitHedging(t *testing.T) {
+	suite.Run(t, new(TestUnitHedgingSuite))
+}
+
+type TestUnitHedgingSuite struct {
+	suite.Suite
+}
+
+// delayedRecognizer 等待 delay 后返回 text 的测试用识别器，ctx 被取消时提前返回并计数
+func delayedRecognizer(delay time.Duration, text string, canceled *int32) *fakeRecognizer {
+	return newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		select {
+		case <-time.After(delay):
+			return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK, Result: text}, nil
+		case <-ctx.Done():
+			if canceled != nil {
+				atomic.AddInt32(canceled, 1)
+			}
+			return nil, ctx.Err()
+		}
+	})
+}
+
+func (t *TestUnitHedgingSuite) TestHedgeWins() {
+	var canceled int32
+	slow := delayedRecognizer(time.Second, "slow", &canceled)
+	fast := delayedRecognizer(0, "fast", nil)
+	hedger, err := NewHedger([]ISpeechRecognizer{slow, fast}, HedgingConfig{InitialDelay: 20 * time.Millisecond})
+	t.NoError(err)
+
+	start := time.Now()
+	rsp, err := hedger.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal("fast", rsp.Result)
+	t.True(time.Since(start) < 500*time.Millisecond)
+
+	stats := hedger.Stats()
+	t.Equal(uint64(1), stats.Requests)
+	t.Equal(uint64(1), stats.Hedges)
+	t.Equal(uint64(1), stats.HedgeWins)
+
+	// 失败的原请求被取消
+	t.Eventually(func() bool {
+		return atomic.LoadInt32(&canceled) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func (t *TestUnitHedgingSuite) TestExtraLoadCap() {
+	slow := delayedRecognizer(30*time.Millisecond, "slow", nil)
+	hedger, err := NewHedger([]ISpeechRecognizer{slow}, HedgingConfig{
+		InitialDelay: time.Millisecond,
+		MinDelay:     time.Millisecond,
+		MaxExtraLoad: 0.1,
+	})
+	t.NoError(err)
+
+	for i := 0; i < 10; i += 1 {
+		_, err := hedger.RecogniteLanguage(nil)
+		t.NoError(err)
+	}
+
+	// 初始预算1次，每次调用增加0.1次
+	stats := hedger.Stats()
+	t.Equal(uint64(10), stats.Requests)
+	t.Equal(uint64(2), stats.Hedges)
+}
+
+func (t *TestUnitHedgingSuite) TestPercentileDelay() {
+	fast := delayedRecognizer(time.Millisecond, "fast", nil)
+	hedger, err := NewHedger([]ISpeechRecognizer{fast}, HedgingConfig{InitialDelay: time.Second})
+	t.NoError(err)
+
+	for i := 0; i < 2*hedgeRecalcEvery; i += 1 {
+		_, err := hedger.RecogniteLanguage(nil)
+		t.NoError(err)
+	}
+
+	delay := hedger.Stats().Delay
+	t.True(delay >= defaultHedgeMinDelay)
+	t.True(delay < time.Second)
+	t.Equal(uint64(0), hedger.Stats().Hedges)
+}
+
+func (t *TestUnitHedgingSuite) TestClientErrorReturnedImmediately() {
+	failing := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return nil, &common.APIError{StatusCode: common.APIStatusCodeClientErrorFormat}
+	})
+	hedger, err := NewHedger([]ISpeechRecognizer{failing}, HedgingConfig{})
+	t.NoError(err)
+
+	_, err = hedger.RecogniteLanguage(nil)
+	t.ErrorIs(err, common.ErrClientFormat)
+	t.Equal(1, failing.calls)
+}
@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// httpBody 可以重复生成的HTTP请求体，每次尝试请求时都重新生成
+type httpBody struct {
+	// write 将请求体写入 w
+	write func(w io.Writer) error
+	// length 请求体未压缩时的长度，未知时为-1
+	length int64
+}
+
+// jsonBody 获取将 value 编码为JSON的请求体
+func jsonBody(value interface{}) (httpBody, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return httpBody{}, err
+	}
+
+	return httpBody{
+		write: func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		},
+		length: int64(len(data)),
+	}, nil
+}
+
+// speechBody 获取语音识别接口的JSON请求体，与 common.AsrtAPISpeechRequest 编码得到的内容相同，
+// 采样数据在写入时逐块进行base64编码，不需要在内存中保存完整的base64字符串和JSON请求体
+func speechBody(wavData []byte, frameRate int, channels int, byteWidth int) httpBody {
+	prefix := `{"samples":"`
+	suffix := `","sample_rate":` + strconv.Itoa(frameRate) + `,"channels":` + strconv.Itoa(channels) +
+		`,"byte_width":` + strconv.Itoa(byteWidth) + `}`
+
+	return httpBody{
+		write: func(w io.Writer) error {
+			if _, err := io.WriteString(w, prefix); err != nil {
+				return err
+			}
+			encoder := base64.NewEncoder(base64.StdEncoding, w)
+			if _, err := encoder.Write(wavData); err != nil {
+				return err
+			}
+			if err := encoder.Close(); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, suffix)
+			return err
+		},
+		length: int64(len(prefix) + base64.StdEncoding.EncodedLen(len(wavData)) + len(suffix)),
+	}
+}
+
+// open 通过 io.Pipe 在后台写入请求体，compression 为 CompressionGzip 时写入gzip压缩后的内容，
+// 返回读取端和请求体长度，压缩时长度未知，为-1
+func (b httpBody) open(compression string) (io.ReadCloser, int64) {
+	reader, writer := io.Pipe()
+	go func() {
+		if compression != CompressionGzip {
+			writer.CloseWithError(b.write(writer))
+			return
+		}
+
+		// 音频数据的压缩率不高，使用最快的压缩级别
+		gzipWriter, _ := gzip.NewWriterLevel(writer, gzip.BestSpeed)
+		err := b.write(gzipWriter)
+		if closeErr := gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+		writer.CloseWithError(err)
+	}()
+
+	if compression == CompressionGzip {
+		return reader, -1
+	}
+	return reader, b.length
+}
@@ -1,192 +1,266 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net/http"
 	"strings"
 
 	"github.com/nl8590687/asrt-sdk-go/common"
 )
 
-var wavDataMaxLength = 16000 * 2 * 16
-
 // HTTPSpeechRecognizer 调用ASRT语音识别系统HTTP+JSON协议接口的语音识别类
 type HTTPSpeechRecognizer struct {
 	BaseSpeechRecognizer
 	// SubPath HTTP协议资源子路径，默认为""
 	SubPath string
-}
 
-// NewHTTPSpeechRecognizer 构造一个用于调用http+json协议接口的语音识别类实例对象
-func NewHTTPSpeechRecognizer(host string, port string, protocol string, subPath string) *HTTPSpeechRecognizer {
-	protocol = strings.ToLower(protocol)
-	if protocol != "http" && protocol != "https" {
-		return nil
-	}
+	client *http.Client
+}
 
+// NewHTTPSpeechRecognizer 构造一个用于调用http+json协议接口的语音识别类实例对象，
+// 协议不支持、TLS配置加载失败或代理配置不合法时返回nil，需要具体错误信息时请使用 NewSpeechRecognizerFromURL
+func NewHTTPSpeechRecognizer(host string, port string, protocol string, subPath string,
+	opts ...Option,
+) *HTTPSpeechRecognizer {
 	base := BaseSpeechRecognizer{
 		Host:     host,
 		Port:     port,
-		Protocol: protocol,
+		Protocol: strings.ToLower(protocol),
+		options:  newOptions(opts...),
 	}
-	httpSpeechRecognizer := HTTPSpeechRecognizer{
-		BaseSpeechRecognizer: base,
-		SubPath:              subPath,
+	httpSpeechRecognizer, err := newHTTPSpeechRecognizer(base, subPath)
+	if err != nil {
+		base.logger().Error("create http speech recognizer failed", common.F("error", err))
+		return nil
 	}
 
-	return &httpSpeechRecognizer
-}
-
-func (h *HTTPSpeechRecognizer) getURL() string {
-	return fmt.Sprintf("%s://%s:%s%s", h.Protocol, h.Host, h.Port, h.SubPath)
+	return httpSpeechRecognizer
 }
 
-// Recognite 调用ASRT语音识别
-func (h *HTTPSpeechRecognizer) Recognite(wavData []byte, frameRate int, channels int, byteWidth int,
-) (*common.AsrtAPIResponse, error) {
-	if len(wavData) > wavDataMaxLength {
-		return nil, fmt.Errorf("error: %s `%d`, %s `%d`",
-			"Too long wave sample byte length:", len(wavData),
-			"the max length is", wavDataMaxLength)
+// newHTTPSpeechRecognizer 根据已经解析好的配置构造HTTP协议的语音识别类实例对象
+func newHTTPSpeechRecognizer(base BaseSpeechRecognizer, subPath string) (*HTTPSpeechRecognizer, error) {
+	if base.Protocol != "http" && base.Protocol != "https" {
+		return nil, fmt.Errorf("error: unsupported http protocol `%s`", base.Protocol)
 	}
 
-	requestBody := common.AsrtAPISpeechRequest{
-		Samples:    common.BytesToBase64(wavData),
-		SampleRate: frameRate,
-		Channels:   channels,
-		ByteWidth:  byteWidth,
+	if err := base.options.checkHTTPCompression(); err != nil {
+		return nil, err
 	}
-
-	byteForm, err := json.Marshal(requestBody)
-	if err != nil {
+	if err := base.options.httpPaths.check(); err != nil {
 		return nil, err
 	}
-
-	contentType := "application/json"
-	url := fmt.Sprintf("%s/all", h.getURL())
-	rspBody, err := common.SendHTTPRequest(url, "POST", byteForm, contentType)
-	if err != nil {
+	if err := base.options.apiVersion.checkPaths(base.options.httpPaths); err != nil {
 		return nil, err
 	}
-
-	responseBody := common.AsrtAPIResponse{}
-	err = json.Unmarshal(rspBody, &responseBody)
+	if err := checkHTTPAuth(base.Protocol, base.options); err != nil {
+		return nil, err
+	}
+	client, err := base.options.newHTTPClient(base.Protocol)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Println("info: recv: ", responseBody)
-	return &responseBody, nil
+	httpSpeechRecognizer := HTTPSpeechRecognizer{
+		BaseSpeechRecognizer: base,
+		SubPath:              subPath,
+		client:               client,
+	}
+
+	return &httpSpeechRecognizer, nil
 }
 
-// RecogniteSpeech 调用ASRT语音识别声学模型
-func (h *HTTPSpeechRecognizer) RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int,
-) (*common.AsrtAPIResponse, error) {
-	if len(wavData) > wavDataMaxLength {
-		return nil, fmt.Errorf("error: %s `%d`, %s `%d`",
-			"Too long wave sample byte length:", len(wavData),
-			"the max length is", wavDataMaxLength)
+// CloseIdleConnections 关闭连接池中的空闲连接，正在进行的请求不受影响
+func (h *HTTPSpeechRecognizer) CloseIdleConnections() {
+	if h.client != nil {
+		h.client.CloseIdleConnections()
 	}
+}
 
-	requestBody := common.AsrtAPISpeechRequest{
-		Samples:    common.BytesToBase64(wavData),
-		SampleRate: frameRate,
-		Channels:   channels,
-		ByteWidth:  byteWidth,
+// checkHTTPAuth 检查配置的认证信息是否会以明文传输，
+// 未使用TLS的http协议只有通过 WithInsecureAuth 显式允许时才能携带认证信息
+func checkHTTPAuth(protocol string, o options) error {
+	if o.hasAuth() && !strings.EqualFold(protocol, "https") && !o.insecureAuth {
+		return fmt.Errorf("error: refuse to send credentials over insecure http, "+
+			"use https or allow it explicitly with WithInsecureAuth: %w", common.ErrUnsupportedConfig)
 	}
 
-	byteForm, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, err
-	}
+	return nil
+}
 
-	contentType := "application/json"
-	url := fmt.Sprintf("%s/speech", h.getURL())
-	rspBody, err := common.SendHTTPRequest(url, "POST", byteForm, contentType)
-	if err != nil {
+func (h *HTTPSpeechRecognizer) getURL() string {
+	return fmt.Sprintf("%s://%s:%s%s", h.Protocol, h.Host, h.Port, h.SubPath)
+}
+
+// Recognite 调用ASRT语音识别
+//
+// 服务端返回非成功状态时返回的错误为 *common.APIError，网络传输出错时为 *common.TransportError；
+// 服务端只返回部分识别结果时，同时返回响应对象和属于 common.ErrPartialResult 分类的错误
+func (h *HTTPSpeechRecognizer) Recognite(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return h.RecogniteContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteContext 调用ASRT语音识别，可以通过 ctx 控制超时和取消
+func (h *HTTPSpeechRecognizer) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := checkWavDataLength(wavData); err != nil {
 		return nil, err
 	}
 
-	responseBody := common.AsrtAPIResponse{}
-	err = json.Unmarshal(rspBody, &responseBody)
-	if err != nil {
+	return h.post(ctx, "all", speechBody(wavData, frameRate, channels, byteWidth))
+}
+
+// RecogniteSpeech 调用ASRT语音识别声学模型
+func (h *HTTPSpeechRecognizer) RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return h.RecogniteSpeechContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteSpeechContext 调用ASRT语音识别声学模型，可以通过 ctx 控制超时和取消
+func (h *HTTPSpeechRecognizer) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := checkWavDataLength(wavData); err != nil {
 		return nil, err
 	}
 
-	log.Println("info: recv: ", responseBody)
-	return &responseBody, nil
+	return h.post(ctx, "speech", speechBody(wavData, frameRate, channels, byteWidth))
 }
 
 // RecogniteLanguage 调用ASRT语音识别语言模型
 func (h *HTTPSpeechRecognizer) RecogniteLanguage(sequencePinyin []string) (*common.AsrtAPIResponse, error) {
-	requestBody := common.AsrtAPILanguageRequest{
-		SequencePinyin: sequencePinyin,
-	}
+	return h.RecogniteLanguageContext(context.Background(), sequencePinyin)
+}
 
-	byteForm, err := json.Marshal(requestBody)
+// RecogniteLanguageContext 调用ASRT语音识别语言模型，可以通过 ctx 控制超时和取消
+func (h *HTTPSpeechRecognizer) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	requestBody, err := jsonBody(common.AsrtAPILanguageRequest{
+		SequencePinyin: sequencePinyin,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	contentType := "application/json"
-	url := fmt.Sprintf("%s/language", h.getURL())
-	rspBody, err := common.SendHTTPRequest(url, "POST", byteForm, contentType)
+	return h.post(ctx, "language", requestBody)
+}
+
+// post 向操作 op 对应路径的接口发送JSON请求并解析ASRT接口响应，按重试策略自动重试，
+// 请求体在发送时流式生成，每次尝试都重新生成
+func (h *HTTPSpeechRecognizer) post(ctx context.Context, op string, requestBody httpBody,
+) (*common.AsrtAPIResponse, error) {
+	compression := h.options.compression
+	url := h.options.apiVersion.requestURL(h.getURL(), h.options.httpPaths.path(op))
+	return h.invoke(ctx, op, func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		body, length := requestBody.open(compression)
+		req, err := http.NewRequestWithContext(ctx, "POST", url, body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		req.ContentLength = length
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, _ := requestBody.open(compression)
+			return body, nil
+		}
+		req.Header.Set("Content-Type", "application/json")
+		h.options.apiVersion.apply(req.Header)
+		for key, value := range h.options.requestMetadata(ctx) {
+			req.Header.Set(key, value)
+		}
+		if compression == CompressionGzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		// Protocol 是导出字段，构造后仍可能被修改为http，因此每次请求都重新检查
+		if err := checkHTTPAuth(h.Protocol, h.options); err != nil {
+			body.Close()
+			return nil, err
+		}
+		headers, err := h.options.authMetadata(ctx)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rsp, err := common.DoHTTPRequest(h.client, req)
+		if err != nil {
+			h.logger().Warn("http request failed", common.F("op", op), common.F("url", url),
+				common.F("request_id", RequestIDFromContext(ctx)), common.F("error", err))
+			return nil, &common.TransportError{Op: op, Err: err}
+		}
+		if rsp.StatusCode != http.StatusOK {
+			h.logger().Warn("unexpected http status", common.F("op", op), common.F("url", url),
+				common.F("request_id", RequestIDFromContext(ctx)), common.F("http_status", rsp.StatusCode))
+		}
+
+		return h.parseResponse(op, rsp)
+	})
+}
+
+// parseResponse 解析HTTP响应为ASRT接口响应
+func (h *HTTPSpeechRecognizer) parseResponse(op string, rsp *common.HTTPResponse) (*common.AsrtAPIResponse, error) {
+	responseBody := &common.AsrtAPIResponse{}
+	err := json.Unmarshal(rsp.Body, responseBody)
+	responseBody.RequestID = rsp.Header.Get(h.options.requestIDHeaderName())
+	responseBody.ServerTiming = common.ParseServerTiming(rsp.Header.Values("Server-Timing")...)
+	if rsp.StatusCode != http.StatusOK {
+		if err != nil {
+			responseBody = nil
+		}
+		apiError := common.NewHTTPStatusError(rsp.StatusCode, responseBody)
+		// 响应体不是合法的JSON时没有响应对象，直接在错误中保留服务端回传的请求ID
+		apiError.RequestID = rsp.Header.Get(h.options.requestIDHeaderName())
+		return nil, apiError
+	}
 	if err != nil {
-		return nil, err
+		return nil, &common.APIError{
+			StatusCode: common.APIStatusCodeServerError,
+			Message:    fmt.Sprintf("invalid response body, %s", err.Error()),
+			HTTPStatus: rsp.StatusCode,
+			RequestID:  rsp.Header.Get(h.options.requestIDHeaderName()),
+		}
 	}
 
-	responseBody := common.AsrtAPIResponse{}
-	err = json.Unmarshal(rspBody, &responseBody)
-	if err != nil {
-		return nil, err
+	h.logger().Debug("recv asrt response", common.F("op", op),
+		common.F("status_code", responseBody.StatusCode), common.F("status_message", responseBody.StatucMesaage))
+	response, err := checkAPIResponse(responseBody)
+	var apiError *common.APIError
+	if errors.As(err, &apiError) {
+		apiError.HTTPStatus = rsp.StatusCode
 	}
 
-	log.Println("info: recv: ", responseBody)
-	return &responseBody, nil
+	return response, err
 }
 
 // RecogniteLong 调用ASRT语音识别来识别长音频序列
 func (h *HTTPSpeechRecognizer) RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int,
 ) ([]*common.AsrtAPIResponse, error) {
-	if frameRate != 16000 {
-		return nil, fmt.Errorf("error: unsupport wave sample rate `%d`", frameRate)
-	}
-	if channels != 1 {
-		return nil, fmt.Errorf("error: unsupport wave channels number `%d`", channels)
-	}
-	if byteWidth != 2 {
-		return nil, fmt.Errorf("error: unsupport wave byte width `%d`", byteWidth)
-	}
-
-	byteData := wavData
-	var asrtResult []*common.AsrtAPIResponse
-	duration := 2 * 16000 * 10
-
-	index := 0
-	for ; index < len(byteData)/duration+1; index++ {
-		rsp, err := h.Recognite(byteData, frameRate, channels, byteWidth)
-		if err != nil {
-			return asrtResult, err
-		}
-
-		asrtResult = append(asrtResult, rsp)
-	}
+	return h.RecogniteLongContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
 
-	return asrtResult, nil
+// RecogniteLongContext 调用ASRT语音识别来识别长音频序列，可以通过 ctx 控制超时和取消
+func (h *HTTPSpeechRecognizer) RecogniteLongContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteLong(ctx, h.RecogniteContext, wavData, frameRate, channels, byteWidth, opts...)
 }
 
 // RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
 func (h *HTTPSpeechRecognizer) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
-	binData := common.ReadBinFile(filename)
-	wavAudio := common.Wav{}
-	err := wavAudio.Deserialize(binData)
-	if err != nil {
-		return nil, err
-	}
-
-	asrtResult, err := h.RecogniteLong(wavAudio.GetRawSamples(),
-		wavAudio.FrameRate, wavAudio.Channels, wavAudio.SampleWidth)
+	return h.RecogniteFileContext(context.Background(), filename)
+}
 
-	return asrtResult, err
+// RecogniteFileContext 调用ASRT语音识别来识别指定文件名的音频文件，可以通过 ctx 控制超时和取消
+func (h *HTTPSpeechRecognizer) RecogniteFileContext(ctx context.Context, filename string,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteFile(ctx, h.RecogniteContext, filename, opts...)
 }
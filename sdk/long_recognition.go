@@ -0,0 +1,224 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+const (
+	// longSegmentDuration 长音频识别时每个片段的时长，单位：秒
+	longSegmentDuration = 10
+	// minStretchRate 语速归一化时允许的最小语速倍率，即最多放慢一倍
+	minStretchRate = 0.5
+)
+
+// RecogniteLongOption 长音频识别的可选配置项
+type RecogniteLongOption func(*recogniteLongOptions)
+
+type recogniteLongOptions struct {
+	maxSpeechRate    float64
+	targetSpeechRate float64
+	maskDTMF         bool
+}
+
+// WithSpeechRateNormalization 对估计语速超过 maxRate (音节/秒) 的片段，
+// 在识别前做不改变音高的时间伸缩，将其放慢到 targetRate。
+// 识别结果的时间戳始终对应原始音频的时间轴
+func WithSpeechRateNormalization(maxRate float64, targetRate float64) RecogniteLongOption {
+	return func(o *recogniteLongOptions) {
+		o.maxSpeechRate = maxRate
+		o.targetSpeechRate = targetRate
+	}
+}
+
+// WithDTMFMasking 在识别前检测音频中的DTMF按键音并将其替换为静音，以免按键音干扰识别，
+// 检测到的按键通过各个识别结果的 Segment.DTMF 返回
+func WithDTMFMasking() RecogniteLongOption {
+	return func(o *recogniteLongOptions) {
+		o.maskDTMF = true
+	}
+}
+
+// recogniteFunc 识别单段音频的函数
+type recogniteFunc func(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error)
+
+// recogniteFile 读取并解码指定文件名的音频文件，再作为长音频序列调用 recognite 识别
+func recogniteFile(ctx context.Context, recognite recogniteFunc, filename string, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	binData := common.ReadBinFile(filename)
+	wavAudio := common.Wav{}
+	err := wavAudio.Deserialize(binData)
+	if err != nil {
+		return nil, err
+	}
+
+	return recogniteLong(ctx, recognite, wavAudio.GetRawSamples(),
+		wavAudio.FrameRate, wavAudio.Channels, wavAudio.SampleWidth, opts...)
+}
+
+// recogniteLong 将长音频序列切分为多个片段后依次调用 recognite 识别，
+// 某个片段只得到部分识别结果时继续识别后续片段，最后返回属于 common.ErrPartialResult 分类的错误
+func recogniteLong(ctx context.Context, recognite recogniteFunc, wavData []byte, frameRate int, channels int, byteWidth int,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	if frameRate != 16000 {
+		return nil, fmt.Errorf("error: unsupport wave sample rate `%d`: %w", frameRate, common.ErrUnsupportedConfig)
+	}
+	if channels != 1 {
+		return nil, fmt.Errorf("error: unsupport wave channels number `%d`: %w", channels, common.ErrUnsupportedConfig)
+	}
+	if byteWidth != 2 {
+		return nil, fmt.Errorf("error: unsupport wave byte width `%d`: %w", byteWidth, common.ErrUnsupportedConfig)
+	}
+
+	options := recogniteLongOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.maxSpeechRate > 0 && options.targetSpeechRate <= 0 {
+		return nil, fmt.Errorf("error: invalid target speech rate `%f`: %w", options.targetSpeechRate, common.ErrClient)
+	}
+
+	var tones []common.DTMFTone
+	if options.maskDTMF {
+		wave, err := common.NewWavFromRawSamples(wavData, frameRate, channels, byteWidth)
+		if err != nil {
+			return nil, err
+		}
+		masked, detected, err := wave.MaskDTMF()
+		if err != nil {
+			return nil, err
+		}
+		wavData = masked.GetRawSamples()
+		tones = detected
+	}
+
+	var asrtResult []*common.AsrtAPIResponse
+	var partialErr error
+	segmentLength := longSegmentDuration * frameRate * channels * byteWidth
+
+	for start := 0; start < len(wavData); start += segmentLength {
+		end := start + segmentLength
+		if end > len(wavData) {
+			end = len(wavData)
+		}
+
+		segment, err := common.NewWavFromRawSamples(wavData[start:end], frameRate, channels, byteWidth)
+		if err != nil {
+			return asrtResult, err
+		}
+		info := &common.AsrtSegmentInfo{
+			Start:       bytesToDuration(start, frameRate, channels, byteWidth),
+			End:         bytesToDuration(end, frameRate, channels, byteWidth),
+			StretchRate: 1,
+		}
+
+		pieces, err := normalizeSpeechRate(segment, info, options)
+		if err != nil {
+			return asrtResult, err
+		}
+
+		for _, piece := range pieces {
+			if options.maskDTMF {
+				piece.info.DTMF = tonesInRange(tones, piece.info.Start, piece.info.End)
+			}
+
+			if err := ctx.Err(); err != nil {
+				return asrtResult, err
+			}
+
+			rsp, err := recognite(ctx, piece.wave.GetRawSamples(), frameRate, channels, byteWidth)
+			if err != nil && rsp != nil && errors.Is(err, common.ErrPartialResult) {
+				partialErr = err
+			} else if err != nil {
+				return asrtResult, err
+			}
+
+			rsp.Segment = piece.info
+			asrtResult = append(asrtResult, rsp)
+		}
+	}
+
+	return asrtResult, partialErr
+}
+
+// longPiece 实际发送识别的音频片段
+type longPiece struct {
+	wave common.Wav
+	info *common.AsrtSegmentInfo
+}
+
+// normalizeSpeechRate 按需对语速过快的片段做时间伸缩，
+// 伸缩后超过单次识别最大长度的片段会被再次切分，并将时间戳映射回原始时间轴
+func normalizeSpeechRate(segment common.Wav, info *common.AsrtSegmentInfo, options recogniteLongOptions,
+) ([]longPiece, error) {
+	if options.maxSpeechRate <= 0 {
+		return []longPiece{{wave: segment, info: info}}, nil
+	}
+
+	buffer, err := segment.ToFloatBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	info.SpeechRate = buffer.EstimateSpeechRate()
+	if info.SpeechRate <= options.maxSpeechRate {
+		return []longPiece{{wave: segment, info: info}}, nil
+	}
+
+	rate := math.Max(options.targetSpeechRate/info.SpeechRate, minStretchRate)
+	if rate >= 1 {
+		return []longPiece{{wave: segment, info: info}}, nil
+	}
+
+	stretched, err := buffer.TimeStretch(rate)
+	if err != nil {
+		return nil, err
+	}
+	info.StretchRate = rate
+
+	var pieces []longPiece
+	pieceFrames := longSegmentDuration * stretched.FrameRate
+	for start := 0; start < stretched.NumFrames(); start += pieceFrames {
+		piece := stretched.Slice(start, start+pieceFrames)
+		pieceInfo := *info
+		// 伸缩后时间轴上的t对应原始时间轴上的 t * rate
+		pieceInfo.Start = info.Start + scaleDuration(common.FramesToDuration(start, stretched.FrameRate), rate)
+		pieceInfo.End = info.Start + scaleDuration(common.FramesToDuration(start+piece.NumFrames(), stretched.FrameRate), rate)
+		if pieceInfo.End > info.End {
+			pieceInfo.End = info.End
+		}
+
+		pieces = append(pieces, longPiece{wave: piece.ToWav(), info: &pieceInfo})
+	}
+
+	return pieces, nil
+}
+
+// tonesInRange 筛选起始时间在 [start, end) 范围内的按键音
+func tonesInRange(tones []common.DTMFTone, start time.Duration, end time.Duration) []common.DTMFTone {
+	result := []common.DTMFTone{}
+	for _, tone := range tones {
+		if tone.Start >= start && tone.Start < end {
+			result = append(result, tone)
+		}
+	}
+
+	return result
+}
+
+// bytesToDuration 将原始采样数据的字节偏移换算为时间
+func bytesToDuration(offset int, frameRate int, channels int, byteWidth int) time.Duration {
+	return common.FramesToDuration(offset/(channels*byteWidth), frameRate)
+}
+
+// scaleDuration 将时间长度乘以给定的倍率
+func scaleDuration(d time.Duration, rate float64) time.Duration {
+	return time.Duration(math.Round(float64(d) * rate))
+}
@@ -0,0 +1,154 @@
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitLongRecognition(t *testing.T) {
+	suite.Run(t, new(TestUnitLongRecognitionSuite))
+}
+
+type TestUnitLongRecognitionSuite struct {
+	suite.Suite
+	mutex   sync.Mutex
+	samples [][]byte
+	lengths []int
+}
+
+// start 启动记录每次请求音频数据的测试用HTTP服务端，返回识别器
+func (t *TestUnitLongRecognitionSuite) start() *HTTPSpeechRecognizer {
+	t.samples = nil
+	t.lengths = nil
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		request := common.AsrtAPISpeechRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err == nil {
+			samples, _ := base64.StdEncoding.DecodeString(request.Samples)
+			t.mutex.Lock()
+			t.samples = append(t.samples, samples)
+			t.lengths = append(t.lengths, len(samples))
+			t.mutex.Unlock()
+		}
+		fakeHTTPHandler(w, r)
+	})
+
+	return NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+}
+
+// syllables 生成模拟语速为 rate 音节/秒的语音，每个音节由正弦包络调制的200Hz正弦波构成
+func syllables(rate float64, duration time.Duration) []byte {
+	buffer := common.NewFloatBuffer(16000, 1, int(duration*16000/time.Second))
+	period := int(16000 / rate)
+	for n := range buffer.Samples[0] {
+		position := n % period
+		if position < period*4/5 {
+			envelope := math.Sin(math.Pi * float64(position) / float64(period*4/5))
+			buffer.Samples[0][n] = float32(0.5 * envelope * math.Sin(2*math.Pi*200*float64(n)/16000))
+		}
+	}
+
+	wave := buffer.ToWav()
+	return wave.GetRawSamples()
+}
+
+func (t *TestUnitLongRecognitionSuite) TestSpeechRateNormalization() {
+	recognizer := t.start()
+	wavData := syllables(6, 12*time.Second)
+
+	results, err := recognizer.RecogniteLongContext(context.Background(), wavData, 16000, 1, 2, WithSpeechRateNormalization(5, 3))
+	t.NoError(err)
+
+	// 前10秒被放慢为20秒，再切分为两个10秒的片段，后2秒被放慢为4秒
+	t.Len(results, 3)
+	t.Len(t.lengths, 3)
+	t.InDelta(20*16000*2, t.lengths[0]+t.lengths[1], 2*16000*2)
+	t.InDelta(4*16000*2, t.lengths[2], 16000*2)
+
+	first, second, last := results[0].Segment, results[1].Segment, results[2].Segment
+	t.Greater(first.SpeechRate, 5.0)
+	t.Equal(first.StretchRate, second.StretchRate)
+	t.InDelta(0.5, first.StretchRate, 0.1)
+
+	// 时间戳映射回原始时间轴，且首尾相接
+	t.Equal(time.Duration(0), first.Start)
+	t.Equal(first.End, second.Start)
+	t.Equal(10*time.Second, second.End)
+	t.Equal(10*time.Second, last.Start)
+	t.Equal(12*time.Second, last.End)
+	t.InDelta(float64(scaleDuration(10*time.Second, first.StretchRate)), float64(first.End), float64(time.Millisecond))
+}
+
+func (t *TestUnitLongRecognitionSuite) TestSpeechRateBelowLimit() {
+	recognizer := t.start()
+	wavData := syllables(3, 12*time.Second)
+
+	results, err := recognizer.RecogniteLongContext(context.Background(), wavData, 16000, 1, 2, WithSpeechRateNormalization(5, 3))
+	t.NoError(err)
+	t.Len(results, 2)
+	t.Equal([]int{10 * 16000 * 2, 2 * 16000 * 2}, t.lengths)
+	for _, result := range results {
+		t.Equal(1.0, result.Segment.StretchRate)
+		t.Greater(result.Segment.SpeechRate, 0.0)
+	}
+
+	_, err = recognizer.RecogniteLongContext(context.Background(), wavData, 16000, 1, 2, WithSpeechRateNormalization(5, 0))
+	t.ErrorIs(err, common.ErrClient)
+}
+
+func (t *TestUnitLongRecognitionSuite) TestDTMFMasking() {
+	recognizer := t.start()
+	first, err := common.GenerateDTMF("12", 100*time.Millisecond, 100*time.Millisecond, 0.5, 16000, 1)
+	t.NoError(err)
+	second, err := common.GenerateDTMF("3", 100*time.Millisecond, 0, 0.5, 16000, 1)
+	t.NoError(err)
+
+	// 1s处按下12，11s处按下3，其余部分为静音和正弦波
+	wave := common.GenerateSilence(time.Second, 16000, 1)
+	for _, part := range []common.Wav{
+		first,
+		common.GenerateSine(440, 0.3, 8600*time.Millisecond, 16000, 1),
+		common.GenerateSilence(time.Second, 16000, 1),
+		second,
+		common.GenerateSilence(900*time.Millisecond, 16000, 1),
+	} {
+		t.NoError(wave.AppendWav(part))
+	}
+	wavData := wave.GetRawSamples()
+	original := append([]byte(nil), wavData...)
+
+	results, err := recognizer.RecogniteLongContext(context.Background(), wavData, 16000, 1, 2, WithDTMFMasking())
+	t.NoError(err)
+	t.Len(results, 2)
+
+	digits := func(tones []common.DTMFTone) string {
+		result := ""
+		for _, tone := range tones {
+			result += string(tone.Digit)
+		}
+		return result
+	}
+	t.Equal("12", digits(results[0].Segment.DTMF))
+	t.Equal("3", digits(results[1].Segment.DTMF))
+	t.InDelta(float64(time.Second), float64(results[0].Segment.DTMF[0].Start), float64(20*time.Millisecond))
+	t.InDelta(float64(11*time.Second), float64(results[1].Segment.DTMF[0].Start), float64(20*time.Millisecond))
+
+	// 发送的音频中按键音被替换为静音，其余部分保持不变，调用方的数据也不会被修改
+	t.Equal(make([]byte, 6400), t.samples[0][16000*2:16000*2+6400])
+	t.Equal(make([]byte, 3200), t.samples[1][16000*2:16000*2+3200])
+	t.Equal(wavData[3*16000*2:4*16000*2], t.samples[0][3*16000*2:4*16000*2])
+	t.Equal(original, wavData)
+
+	results, err = recognizer.RecogniteLong(wavData, 16000, 1, 2)
+	t.NoError(err)
+	t.Nil(results[0].Segment.DTMF)
+}
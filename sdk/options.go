@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"net/url"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// Option 语音识别类实例的可选配置项，只对相应协议有意义的配置项会被其他协议的实例忽略
+type Option func(*options)
+
+// options 语音识别类实例的配置
+type options struct {
+	logger    common.Logger
+	retry     *RetryPolicy
+	timeout   time.Duration
+	tls       *TLSConfig
+	tlsConfig *tls.Config
+
+	tokenSource  TokenSource
+	authHeaders  map[string]string
+	insecureAuth bool
+
+	proxy    *url.URL
+	proxySet bool
+
+	httpTransport HTTPTransportConfig
+	keepalive     *KeepaliveConfig
+	compression   string
+	httpPaths     HTTPPaths
+	apiVersion    APIVersion
+
+	requestIDHeader string
+}
+
+// newOptions 使用默认配置并依次应用各个配置项
+func newOptions(opts ...Option) options {
+	o := options{
+		logger: common.NopLogger{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithLogger 设置日志实现，默认不输出任何日志
+func WithLogger(logger common.Logger) Option {
+	return func(o *options) {
+		if logger == nil {
+			logger = common.NopLogger{}
+		}
+		o.logger = logger
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，按重试策略重试时每次尝试分别计时，默认不限制
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitOptions(t *testing.T) {
+	suite.Run(t, new(TestUnitOptionsSuite))
+}
+
+type TestUnitOptionsSuite struct {
+	suite.Suite
+	mutex   sync.Mutex
+	entries []logEntry
+}
+
+// logEntry 测试中记录的一条日志
+type logEntry struct {
+	level  common.LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+// record 获取把日志记录到 entries 中的 Logger
+func (t *TestUnitOptionsSuite) record() common.Logger {
+	t.entries = nil
+	return common.LoggerFunc(func(level common.LogLevel, msg string, fields []common.Field) {
+		entry := logEntry{level: level, msg: msg, fields: map[string]interface{}{}}
+		for _, field := range fields {
+			entry.fields[field.Key] = field.Value
+		}
+		t.mutex.Lock()
+		t.entries = append(t.entries, entry)
+		t.mutex.Unlock()
+	})
+}
+
+// exercise 依次触发成功请求、非200的HTTP状态、连接失败和构造失败，覆盖SDK中的各个日志输出点
+func (t *TestUnitOptionsSuite) exercise(opts ...Option) {
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/all" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fakeHTTPHandler(w, r)
+	})
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", opts...)
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	_, err = recognizer.Recognite(make([]byte, 3200), 16000, 1, 2)
+	t.Error(err)
+
+	closed := NewHTTPSpeechRecognizer("127.0.0.1", "1", "http", "", opts...)
+	_, err = closed.RecogniteLanguage([]string{"ni3"})
+	t.Error(err)
+
+	badTLS := append([]Option{WithTLS(TLSConfig{CAFile: "not-exist.pem"})}, opts...)
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "", badTLS...))
+}
+
+func (t *TestUnitOptionsSuite) TestSilentWithoutLogger() {
+	var out bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&out)
+	defer log.SetOutput(output)
+
+	t.exercise()
+	t.exercise(WithLogger(nil))
+	t.Equal("", out.String())
+}
+
+func (t *TestUnitOptionsSuite) TestWithLogger() {
+	t.exercise(WithLogger(t.record()))
+
+	messages := map[string]logEntry{}
+	for _, entry := range t.entries {
+		messages[entry.msg] = entry
+	}
+
+	debug := messages["recv asrt response"]
+	t.Equal(common.LevelDebug, debug.level)
+	t.Equal("language", debug.fields["op"])
+
+	status := messages["unexpected http status"]
+	t.Equal(common.LevelWarn, status.level)
+	t.Equal("all", status.fields["op"])
+	t.Equal(http.StatusServiceUnavailable, status.fields["http_status"])
+	t.NotEmpty(status.fields["request_id"])
+
+	t.Equal(common.LevelWarn, messages["http request failed"].level)
+	t.NotNil(messages["http request failed"].fields["error"])
+	t.Equal(common.LevelError, messages["create http speech recognizer failed"].level)
+}
+
+func (t *TestUnitOptionsSuite) TestStdLogger() {
+	var out bytes.Buffer
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", "1", "http", "",
+		WithLogger(common.NewStdLogger(log.New(&out, "", 0), common.LevelWarn)))
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.Error(err)
+	t.Contains(out.String(), "warning: http request failed op=language")
+	t.NotContains(out.String(), "debug:")
+}
@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// PreprocessStep 预处理链中的一个步骤，在浮点域中处理音频，返回处理后的音频
+type PreprocessStep func(buffer *common.FloatBuffer) (*common.FloatBuffer, error)
+
+// PreprocessMono 将多声道音频混合为单声道
+func PreprocessMono() PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		return buffer.MixToMono(), nil
+	}
+}
+
+// PreprocessResample 将音频重采样到 frameRate 采样频率，ASRT服务端要求16000Hz
+func PreprocessResample(frameRate int) PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		return buffer.Resample(frameRate)
+	}
+}
+
+// PreprocessHighPass 使用截止频率为 cutoff (Hz) 的高通滤波器去除低频噪声，例如工频干扰，
+// cutoff 不低于音频的奈奎斯特频率时返回属于 common.ErrUnsupportedConfig 分类的错误
+func PreprocessHighPass(cutoff float64) PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		if nyquist := float64(buffer.FrameRate) / 2; cutoff >= nyquist {
+			return nil, fmt.Errorf("error: highpass cutoff `%v` Hz is not below the nyquist frequency `%v` Hz: %w",
+				cutoff, nyquist, common.ErrUnsupportedConfig)
+		}
+		return buffer.HighPass(cutoff), nil
+	}
+}
+
+// PreprocessLowPass 使用截止频率为 cutoff (Hz) 的低通滤波器去除高频噪声，
+// cutoff 不低于音频的奈奎斯特频率时音频中没有需要去除的成分，原样返回
+func PreprocessLowPass(cutoff float64) PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		if cutoff >= float64(buffer.FrameRate)/2 {
+			return buffer, nil
+		}
+		return buffer.LowPass(cutoff), nil
+	}
+}
+
+// PreprocessGain 对音频施加固定增益，单位：dB
+func PreprocessGain(gainDB float64) PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		return scaleBuffer(buffer, math.Pow(10, gainDB/20)), nil
+	}
+}
+
+// PreprocessNormalize 将音频的峰值归一化到 peakDB (dBFS，不大于0)，静音音频保持不变
+func PreprocessNormalize(peakDB float64) PreprocessStep {
+	return func(buffer *common.FloatBuffer) (*common.FloatBuffer, error) {
+		peak := 0.0
+		for _, samples := range buffer.Samples {
+			for _, sample := range samples {
+				peak = math.Max(peak, math.Abs(float64(sample)))
+			}
+		}
+		if peak == 0 {
+			return buffer, nil
+		}
+
+		return scaleBuffer(buffer, math.Pow(10, peakDB/20)/peak), nil
+	}
+}
+
+// scaleBuffer 获取将所有采样值乘以 gain 后的音频
+func scaleBuffer(buffer *common.FloatBuffer, gain float64) *common.FloatBuffer {
+	output := buffer.Clone()
+	for _, samples := range output.Samples {
+		for n := range samples {
+			samples[n] *= float32(gain)
+		}
+	}
+
+	return output
+}
+
+// Preprocessor 在发送给ASRT服务端之前对音频执行预处理链的语音识别包装类，
+// 例如将任意采样频率的立体声录音转换为16000Hz单声道并滤除噪声
+//
+// 长音频识别会先对整段音频做预处理再切分，因此输入的长音频也可以不是16000Hz单声道
+type Preprocessor struct {
+	recognizerMixin
+	next  ContextSpeechRecognizer
+	steps []PreprocessStep
+}
+
+// NewPreprocessor 构造一个按顺序执行 steps 后再调用 next 的预处理包装类实例
+func NewPreprocessor(next ISpeechRecognizer, steps ...PreprocessStep) *Preprocessor {
+	p := &Preprocessor{next: withContext(next), steps: steps}
+	p.recognizerMixin = recognizerMixin{self: p}
+
+	return p
+}
+
+// process 对PCM音频执行预处理链，返回处理后的音频及其格式，
+// 执行过预处理时输出16bit PCM音频，预处理链为空时原样返回输入的音频
+func (p *Preprocessor) process(wavData []byte, frameRate int, channels int, byteWidth int,
+) ([]byte, int, int, int, error) {
+	if len(p.steps) == 0 {
+		return wavData, frameRate, channels, byteWidth, nil
+	}
+
+	wave, err := common.NewWavFromRawSamples(wavData, frameRate, channels, byteWidth)
+	if err != nil {
+		return nil, 0, 0, 0, fmt.Errorf("error: preprocess audio failed, %s: %w", err, common.ErrUnsupportedConfig)
+	}
+	buffer, err := wave.ToFloatBuffer()
+	if err != nil {
+		return nil, 0, 0, 0, fmt.Errorf("error: preprocess audio failed, %s: %w", err, common.ErrUnsupportedConfig)
+	}
+	for _, step := range p.steps {
+		buffer, err = step(buffer)
+		if err != nil {
+			return nil, 0, 0, 0, fmt.Errorf("error: preprocess audio failed, %w", err)
+		}
+	}
+	output := buffer.ToWav()
+
+	return output.GetRawSamples(), output.FrameRate, output.Channels, output.SampleWidth, nil
+}
+
+// RecogniteContext 调用ASRT语音识别，可以通过 ctx 控制超时和取消
+func (p *Preprocessor) RecogniteContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	wavData, frameRate, channels, byteWidth, err := p.process(wavData, frameRate, channels, byteWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.next.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteSpeechContext 调用ASRT语音识别声学模型，可以通过 ctx 控制超时和取消
+func (p *Preprocessor) RecogniteSpeechContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	wavData, frameRate, channels, byteWidth, err := p.process(wavData, frameRate, channels, byteWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.next.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLanguageContext 调用ASRT语音识别语言模型，可以通过 ctx 控制超时和取消
+func (p *Preprocessor) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return p.next.RecogniteLanguageContext(ctx, sequencePinyin)
+}
+
+// RecogniteLong 调用ASRT语音识别来识别长音频序列
+func (p *Preprocessor) RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int,
+) ([]*common.AsrtAPIResponse, error) {
+	return p.RecogniteLongContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLongContext 对整段音频做预处理后切分识别，可以通过 ctx 控制超时和取消
+func (p *Preprocessor) RecogniteLongContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	wavData, frameRate, channels, byteWidth, err := p.process(wavData, frameRate, channels, byteWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	return recogniteLong(ctx, p.next.RecogniteContext, wavData, frameRate, channels, byteWidth, opts...)
+}
+
+// RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
+func (p *Preprocessor) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
+	return p.RecogniteFileContext(context.Background(), filename)
+}
+
+// RecogniteFileContext 调用ASRT语音识别来识别指定文件名的音频文件，可以通过 ctx 控制超时和取消
+func (p *Preprocessor) RecogniteFileContext(ctx context.Context, filename string,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	wave, err := DecodeWav(common.ReadBinFile(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.RecogniteLongContext(ctx, wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth, opts...)
+}
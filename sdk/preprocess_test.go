@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// recordedAudio 测试用识别器收到的一次音频数据及其格式
+type recordedAudio struct {
+	data      []byte
+	frameRate int
+	channels  int
+	byteWidth int
+}
+
+// formatRecorder 记录收到的音频格式的测试用识别器
+type formatRecorder struct {
+	recognizerMixin
+	mutex  sync.Mutex
+	audio  []recordedAudio
+	pinyin []string
+}
+
+func newFormatRecorder() *formatRecorder {
+	recorder := &formatRecorder{}
+	recorder.recognizerMixin = recognizerMixin{self: recorder}
+
+	return recorder
+}
+
+func (r *formatRecorder) RecogniteContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.audio = append(r.audio, recordedAudio{data: wavData, frameRate: frameRate, channels: channels, byteWidth: byteWidth})
+	return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+}
+
+func (r *formatRecorder) RecogniteSpeechContext(ctx context.Context, wavData []byte, frameRate int, channels int,
+	byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return r.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+}
+
+func (r *formatRecorder) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pinyin = sequencePinyin
+	return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+}
+
+func TestUnitPreprocess(t *testing.T) {
+	suite.Run(t, new(TestUnitPreprocessSuite))
+}
+
+type TestUnitPreprocessSuite struct {
+	suite.Suite
+}
+
+// wave 将收到的16bit音频转换为Wav对象
+func (t *TestUnitPreprocessSuite) wave(audio recordedAudio) common.Wav {
+	wave, err := common.NewWavFromRawSamples(audio.data, audio.frameRate, audio.channels, audio.byteWidth)
+	t.Require().NoError(err)
+
+	return wave
+}
+
+func (t *TestUnitPreprocessSuite) TestConvertFormat() {
+	recorder := newFormatRecorder()
+	preprocessor := NewPreprocessor(recorder, PreprocessMono(), PreprocessResample(16000))
+
+	wave := common.GenerateSine(440, 0.5, time.Second, 44100, 2)
+	_, err := preprocessor.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+	t.Len(recorder.audio, 1)
+	t.Equal(16000, recorder.audio[0].frameRate)
+	t.Equal(1, recorder.audio[0].channels)
+	t.Equal(2, recorder.audio[0].byteWidth)
+	output := t.wave(recorder.audio[0])
+	t.InDelta(16000, output.NumFrames(), 1)
+
+	_, err = preprocessor.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal([]string{"ni3"}, recorder.pinyin)
+}
+
+func (t *TestUnitPreprocessSuite) TestLongAudio() {
+	recorder := newFormatRecorder()
+	preprocessor := NewPreprocessor(recorder, PreprocessResample(16000))
+
+	// 8000Hz的长音频先整体重采样，再按16000Hz切分
+	wave := common.GenerateSine(440, 0.5, 3*time.Second, 8000, 1)
+	_, err := preprocessor.RecogniteLong(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+	t.NotEmpty(recorder.audio)
+	for _, piece := range recorder.audio {
+		t.Equal(16000, piece.frameRate)
+	}
+}
+
+func (t *TestUnitPreprocessSuite) TestNormalizeAndGain() {
+	recorder := newFormatRecorder()
+	preprocessor := NewPreprocessor(recorder, PreprocessNormalize(-6), PreprocessGain(6))
+
+	wave := common.GenerateSine(440, 0.1, 100*time.Millisecond, 16000, 1)
+	_, err := preprocessor.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+
+	output := t.wave(recorder.audio[0])
+	buffer, err := output.ToFloatBuffer()
+	t.NoError(err)
+	peak := float32(0)
+	for _, sample := range buffer.Samples[0] {
+		if sample > peak {
+			peak = sample
+		}
+	}
+	t.InDelta(1.0, peak, 0.01)
+}
+
+func (t *TestUnitPreprocessSuite) TestCutoffAboveNyquist() {
+	recorder := newFormatRecorder()
+	wave := common.GenerateSine(440, 0.5, 100*time.Millisecond, 8000, 1)
+
+	// 低通滤波的截止频率不低于奈奎斯特频率时原样传递音频
+	preprocessor := NewPreprocessor(recorder, PreprocessLowPass(6000))
+	_, err := preprocessor.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.NoError(err)
+	t.Equal(wave.GetRawSamples(), recorder.audio[0].data)
+
+	preprocessor = NewPreprocessor(recorder, PreprocessHighPass(4000))
+	_, err = preprocessor.Recognite(wave.GetRawSamples(), wave.FrameRate, wave.Channels, wave.SampleWidth)
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+	t.Len(recorder.audio, 1)
+}
+
+func (t *TestUnitPreprocessSuite) TestUnsupportedSampleWidth() {
+	preprocessor := NewPreprocessor(newFormatRecorder(), PreprocessMono())
+	_, err := preprocessor.Recognite(make([]byte, 300), 16000, 1, 3)
+	t.ErrorIs(err, common.ErrUnsupportedConfig)
+}
+
+func (t *TestUnitPreprocessSuite) TestEmptyChainKeepsFormat() {
+	recorder := newFormatRecorder()
+	preprocessor := NewPreprocessor(recorder)
+
+	// 预处理链为空时原样传递音频，包括8bit和32bit的采样位深
+	for _, byteWidth := range []int{1, 4} {
+		wavData := make([]byte, 1600*byteWidth)
+		for i := range wavData {
+			wavData[i] = byte(i)
+		}
+		_, err := preprocessor.Recognite(wavData, 8000, 1, byteWidth)
+		t.NoError(err)
+		_, err = preprocessor.RecogniteSpeech(wavData, 8000, 1, byteWidth)
+		t.NoError(err)
+		t.Len(recorder.audio, 2)
+		for _, audio := range recorder.audio {
+			t.Equal(recordedAudio{data: wavData, frameRate: 8000, channels: 1, byteWidth: byteWidth}, audio)
+		}
+		recorder.audio = nil
+	}
+}
@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// WithProxy 通过代理服务器连接ASRT服务端，HTTP和gRPC协议都支持以下两种代理：
+//
+//	http://[user:password@]host:port     HTTP代理，HTTPS和gRPC请求通过 CONNECT 方法建立隧道
+//	socks5://[user:password@]host:port   SOCKS5代理，socks5h 表示由代理服务器解析域名
+//
+// proxyURL 为nil时总是直接连接，忽略代理环境变量。未设置该配置项时，
+// 两种协议都遵循标准的 HTTPS_PROXY、HTTP_PROXY 和 NO_PROXY 环境变量
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *options) {
+		o.proxy = proxyURL
+		o.proxySet = true
+	}
+}
+
+// checkProxy 检查代理地址是否受支持
+func checkProxy(proxyURL *url.URL) error {
+	if proxyURL == nil {
+		return nil
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("error: unsupported proxy scheme `%s`, must be http, socks5 or socks5h", proxyURL.Scheme)
+	}
+	if proxyURL.Hostname() == "" || proxyURL.Port() == "" {
+		return fmt.Errorf("error: proxy url `%s` must be like scheme://host:port", proxyURL.Redacted())
+	}
+
+	return nil
+}
+
+// httpProxy 获取HTTP协议使用的 http.Transport.Proxy 函数
+func (o options) httpProxy() (func(*http.Request) (*url.URL, error), error) {
+	if !o.proxySet {
+		return http.ProxyFromEnvironment, nil
+	}
+	if o.proxy == nil {
+		return nil, nil
+	}
+	if err := checkProxy(o.proxy); err != nil {
+		return nil, err
+	}
+
+	proxyURL := *o.proxy
+	if proxyURL.Scheme == "socks5h" {
+		// net/http 的SOCKS5代理总是由代理服务器解析域名
+		proxyURL.Scheme = "socks5"
+	}
+
+	return http.ProxyURL(&proxyURL), nil
+}
+
+// grpcProxyDialer 获取gRPC协议使用的拨号函数，未设置代理配置项时返回nil，
+// 此时由gRPC自身按环境变量选择代理
+func (o options) grpcProxyDialer() (func(ctx context.Context, address string) (net.Conn, error), error) {
+	if !o.proxySet {
+		return nil, nil
+	}
+
+	var direct net.Dialer
+	if o.proxy == nil {
+		return func(ctx context.Context, address string) (net.Conn, error) {
+			return direct.DialContext(ctx, "tcp", address)
+		}, nil
+	}
+	if err := checkProxy(o.proxy); err != nil {
+		return nil, err
+	}
+
+	proxyURL := o.proxy
+	if proxyURL.Scheme == "http" {
+		return func(ctx context.Context, address string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, proxyURL, address)
+		}, nil
+	}
+
+	dialer, err := proxy.FromURL(proxyURL, &direct)
+	if err != nil {
+		return nil, fmt.Errorf("error: create socks5 proxy dialer failed, %w", err)
+	}
+	contextDialer := dialer.(proxy.ContextDialer)
+
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		return contextDialer.DialContext(ctx, "tcp", address)
+	}, nil
+}
+
+// dialHTTPConnect 通过HTTP代理的 CONNECT 方法建立到 address 的隧道
+func dialHTTPConnect(ctx context.Context, proxyURL *url.URL, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credential := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credential)
+	}
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	rsp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("error: proxy `%s` refused to connect `%s`, %s",
+			proxyURL.Host, address, strings.TrimSpace(rsp.Status))
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn 先读取 reader 中已缓冲数据的连接
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read 实现 net.Conn 接口
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
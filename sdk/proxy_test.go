@@ -0,0 +1,236 @@
+package sdk
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// testProxy 测试用的代理服务端，记录经过代理的连接和请求数
+type testProxy struct {
+	url      *url.URL
+	requests int32
+}
+
+func (p *testProxy) count() int {
+	return int(atomic.LoadInt32(&p.requests))
+}
+
+// pipeConn 在两个连接之间双向转发数据，任意一方关闭时关闭两个连接
+func pipeConn(a net.Conn, b net.Conn) {
+	go func() {
+		_, _ = io.Copy(a, b)
+		a.Close()
+		b.Close()
+	}()
+	_, _ = io.Copy(b, a)
+	a.Close()
+	b.Close()
+}
+
+// startHTTPProxy 启动测试用HTTP代理，支持 CONNECT 隧道和普通HTTP请求转发，
+// auth 不为空时要求 Proxy-Authorization 请求头等于 auth
+func startHTTPProxy(t *testing.T, auth string) *testProxy {
+	p := &testProxy{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != "" && r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		atomic.AddInt32(&p.requests, 1)
+
+		if r.Method != http.MethodConnect {
+			r.RequestURI = ""
+			r.Header.Del("Proxy-Authorization")
+			rsp, err := http.DefaultTransport.RoundTrip(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer rsp.Body.Close()
+			w.WriteHeader(rsp.StatusCode)
+			_, _ = io.Copy(w, rsp.Body)
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		pipeConn(conn, target)
+	}))
+	t.Cleanup(server.Close)
+
+	p.url, _ = url.Parse(server.URL)
+	return p
+}
+
+// startSOCKS5Proxy 启动只支持无认证 CONNECT 命令的测试用SOCKS5代理
+func startSOCKS5Proxy(t *testing.T) *testProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	p := &testProxy{url: &url.URL{Scheme: "socks5", Host: listener.Addr().String()}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go p.serveSOCKS5(conn)
+		}
+	}()
+
+	return p
+}
+
+// serveSOCKS5 处理一个SOCKS5连接
+func (p *testProxy) serveSOCKS5(conn net.Conn) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil || header[0] != 5 {
+		conn.Close()
+		return
+	}
+	methods := make([]byte, header[1])
+	_, _ = io.ReadFull(conn, methods)
+	_, _ = conn.Write([]byte{5, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil || request[1] != 1 {
+		conn.Close()
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		_, _ = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		name := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		conn.Close()
+		return
+	}
+	port := make([]byte, 2)
+	_, _ = io.ReadFull(conn, port)
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+		return
+	}
+	atomic.AddInt32(&p.requests, 1)
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	pipeConn(conn, target)
+}
+
+func TestUnitProxy(t *testing.T) {
+	suite.Run(t, new(TestUnitProxySuite))
+}
+
+type TestUnitProxySuite struct {
+	suite.Suite
+}
+
+func (t *TestUnitProxySuite) TestHTTPThroughHTTPProxy() {
+	_, port := startFakeHTTPServer(t.T(), nil)
+	proxy := startHTTPProxy(t.T(), "")
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithProxy(proxy.url))
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(1, proxy.count())
+
+	// 不使用代理时直接连接
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithProxy(nil))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal(1, proxy.count())
+}
+
+func (t *TestUnitProxySuite) TestGRPCThroughHTTPProxy() {
+	port := startFakeGRPCServer(t.T(), nil)
+	proxy := startHTTPProxy(t.T(), "Basic dXNlcjpwYXNz")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authed := *proxy.url
+	authed.User = url.UserPassword("user", "pass")
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithProxy(&authed))
+	defer recognizer.Close()
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(1, proxy.count())
+
+	// 代理认证失败时无法建立连接
+	unauthed := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithProxy(proxy.url))
+	defer unauthed.Close()
+	shortCtx, shortCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer shortCancel()
+	_, err = unauthed.RecogniteLanguageContext(shortCtx, []string{"ni3"})
+	t.Error(err)
+}
+
+func (t *TestUnitProxySuite) TestSOCKS5() {
+	grpcPort := startFakeGRPCServer(t.T(), nil)
+	_, httpPort := startFakeHTTPServer(t.T(), nil)
+	proxy := startSOCKS5Proxy(t.T())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	grpcRecognizer := NewGRPCSpeechRecognizer("127.0.0.1", grpcPort, "grpc", WithProxy(proxy.url))
+	defer grpcRecognizer.Close()
+	rsp, err := grpcRecognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(1, proxy.count())
+
+	socks5h := *proxy.url
+	socks5h.Scheme = "socks5h"
+	httpRecognizer := NewHTTPSpeechRecognizer("localhost", httpPort, "http", "", WithProxy(&socks5h))
+	rsp, err = httpRecognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(2, proxy.count())
+}
+
+func (t *TestUnitProxySuite) TestInvalidProxy() {
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", "20001", "http", "",
+		WithProxy(&url.URL{Scheme: "ftp", Host: "proxy:21"})))
+	t.Nil(NewGRPCSpeechRecognizer("127.0.0.1", "20002", "grpc", WithProxy(&url.URL{Scheme: "http", Host: "proxy"})))
+
+	_, err := NewSpeechRecognizerFromURL("http://127.0.0.1:20001?proxy=" + url.QueryEscape("https://proxy:3128"))
+	t.Error(err)
+	recognizer, err := NewSpeechRecognizerFromURL("grpc://127.0.0.1:20002?proxy=" +
+		url.QueryEscape("socks5://proxy:1080"))
+	t.NoError(err)
+	recognizer.(*GRPCSpeechRecognizer).Close()
+}
@@ -0,0 +1,236 @@
+package sdk
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// RateLimitConfig 客户端限流配置，各项限制为0时表示不限制
+type RateLimitConfig struct {
+	// RequestsPerSecond 每秒最多发送的请求数
+	RequestsPerSecond float64
+	// RequestBurst 请求数令牌桶的容量，即允许的突发请求数，默认为 RequestsPerSecond 向上取整
+	RequestBurst int
+	// AudioSecondsPerSecond 每秒最多发送的音频时长，单位：秒，语言模型请求不消耗音频时长
+	AudioSecondsPerSecond float64
+	// AudioBurst 音频时长令牌桶的容量，单位：秒，默认为 AudioSecondsPerSecond
+	AudioBurst float64
+	// MaxInFlight 最多同时进行的请求数
+	MaxInFlight int
+}
+
+// tokenBucket 允许预支的令牌桶，令牌不足时返回需要等待的时间
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 构造一个装满令牌的令牌桶，rate 为0时返回nil表示不限制
+func newTokenBucket(rate float64, burst float64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = math.Ceil(rate)
+	}
+
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst}
+}
+
+// reserve 预支 n 个令牌，返回需要等待多久才能使用这些令牌
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// refund 归还预支但未使用的令牌
+func (b *tokenBucket) refund(n float64) {
+	if b == nil {
+		return
+	}
+
+	b.tokens = math.Min(b.burst, b.tokens+n)
+}
+
+// RateLimiter 对请求数、音频时长和并发数做客户端限流的语音识别实例
+//
+// 超出限制的调用会排队等待，等待期间 ctx 被取消时立即返回 ctx.Err()。
+// 长音频识别的每个片段会分别经过限流
+type RateLimiter struct {
+	recognizerMixin
+
+	next      ContextSpeechRecognizer
+	semaphore chan struct{}
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+
+	mutex    sync.Mutex
+	requests *tokenBucket
+	audio    *tokenBucket
+	waiting  int
+}
+
+// NewRateLimiter 构造一个对 next 做客户端限流的语音识别实例
+func NewRateLimiter(next ISpeechRecognizer, config RateLimitConfig) *RateLimiter {
+	limiter := &RateLimiter{
+		next:     withContext(next),
+		requests: newTokenBucket(config.RequestsPerSecond, float64(config.RequestBurst)),
+		audio:    newTokenBucket(config.AudioSecondsPerSecond, config.AudioBurst),
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+	if config.MaxInFlight > 0 {
+		limiter.semaphore = make(chan struct{}, config.MaxInFlight)
+	}
+	limiter.recognizerMixin = recognizerMixin{self: limiter}
+
+	return limiter
+}
+
+// QueueDepth 获取正在排队等待的调用数
+func (l *RateLimiter) QueueDepth() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.waiting
+}
+
+// InFlight 获取正在进行的请求数，未限制并发数时总是返回0
+func (l *RateLimiter) InFlight() int {
+	return len(l.semaphore)
+}
+
+// RecogniteContext 经过限流调用ASRT语音识别
+func (l *RateLimiter) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return l.call(ctx, audioSeconds(wavData, frameRate, channels, byteWidth),
+		func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+			return l.next.RecogniteContext(ctx, wavData, frameRate, channels, byteWidth)
+		})
+}
+
+// RecogniteSpeechContext 经过限流调用ASRT语音识别声学模型
+func (l *RateLimiter) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return l.call(ctx, audioSeconds(wavData, frameRate, channels, byteWidth),
+		func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+			return l.next.RecogniteSpeechContext(ctx, wavData, frameRate, channels, byteWidth)
+		})
+}
+
+// RecogniteLanguageContext 经过限流调用ASRT语音识别语言模型
+func (l *RateLimiter) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	return l.call(ctx, 0, func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		return l.next.RecogniteLanguageContext(ctx, sequencePinyin)
+	})
+}
+
+// call 等待限流放行后调用 fn
+func (l *RateLimiter) call(ctx context.Context, seconds float64,
+	fn func(ctx context.Context) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	if err := l.wait(ctx, seconds); err != nil {
+		return nil, err
+	}
+	if l.semaphore != nil {
+		defer func() {
+			<-l.semaphore
+		}()
+	}
+
+	return fn(ctx)
+}
+
+// wait 等待令牌和并发名额，ctx 被取消时归还已经预支的令牌
+func (l *RateLimiter) wait(ctx context.Context, seconds float64) error {
+	l.mutex.Lock()
+	now := l.now()
+	delay := l.requests.reserve(now, 1)
+	if audioDelay := l.audio.reserve(now, seconds); audioDelay > delay {
+		delay = audioDelay
+	}
+	queued := delay > 0 || (l.semaphore != nil && len(l.semaphore) == cap(l.semaphore))
+	if queued {
+		l.waiting += 1
+	}
+	l.mutex.Unlock()
+
+	if queued {
+		defer func() {
+			l.mutex.Lock()
+			l.waiting -= 1
+			l.mutex.Unlock()
+		}()
+	}
+
+	if delay > 0 {
+		if err := l.sleep(ctx, delay); err != nil {
+			l.refund(seconds)
+			return err
+		}
+	}
+
+	if l.semaphore != nil {
+		select {
+		case <-ctx.Done():
+			l.refund(seconds)
+			return ctx.Err()
+		case l.semaphore <- struct{}{}:
+		}
+	}
+
+	return nil
+}
+
+// refund 归还本次调用预支的请求数和音频时长令牌
+func (l *RateLimiter) refund(seconds float64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.requests.refund(1)
+	l.audio.refund(seconds)
+}
+
+// sleepContext 等待 d 时间，期间 ctx 被取消时立即返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// audioSeconds 计算原始采样数据对应的音频时长，单位：秒
+func audioSeconds(wavData []byte, frameRate int, channels int, byteWidth int) float64 {
+	bytesPerSecond := frameRate * channels * byteWidth
+	if bytesPerSecond <= 0 {
+		return 0
+	}
+
+	return float64(len(wavData)) / float64(bytesPerSecond)
+}
@@ -0,0 +1,170 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitRateLimiter(t *testing.T) {
+	suite.Run(t, new(TestUnitRateLimiterSuite))
+}
+
+type TestUnitRateLimiterSuite struct {
+	suite.Suite
+	mutex  sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// fakeClock 为 limiter 设置测试用的时钟，等待时不实际休眠，只记录等待时间并将时钟向前拨动
+func (t *TestUnitRateLimiterSuite) fakeClock(limiter *RateLimiter) *RateLimiter {
+	t.now = time.Unix(1000, 0)
+	t.sleeps = nil
+	limiter.now = func() time.Time {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		return t.now
+	}
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		t.sleeps = append(t.sleeps, d)
+		t.now = t.now.Add(d)
+		return nil
+	}
+
+	return limiter
+}
+
+func (t *TestUnitRateLimiterSuite) TestRequestsPerSecond() {
+	limiter := t.fakeClock(NewRateLimiter(okRecognizer(), RateLimitConfig{RequestsPerSecond: 20, RequestBurst: 1}))
+
+	for i := 0; i < 5; i += 1 {
+		_, err := limiter.RecogniteLanguage(nil)
+		t.NoError(err)
+	}
+	t.Equal([]time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond,
+		50 * time.Millisecond}, t.sleeps)
+
+	// 空闲期间令牌按速率补充，但不超过桶的容量
+	t.now = t.now.Add(time.Minute)
+	t.sleeps = nil
+	_, err := limiter.RecogniteLanguage(nil)
+	t.NoError(err)
+	_, err = limiter.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Equal([]time.Duration{50 * time.Millisecond}, t.sleeps)
+}
+
+func (t *TestUnitRateLimiterSuite) TestAudioSecondsPerSecond() {
+	limiter := t.fakeClock(NewRateLimiter(okRecognizer(), RateLimitConfig{AudioSecondsPerSecond: 10, AudioBurst: 1}))
+	// 1秒16kHz单声道16位音频
+	wavData := make([]byte, 16000*2)
+
+	for i := 0; i < 3; i += 1 {
+		_, err := limiter.Recognite(wavData, 16000, 1, 2)
+		t.NoError(err)
+	}
+	t.Equal([]time.Duration{100 * time.Millisecond, 100 * time.Millisecond}, t.sleeps)
+
+	// 语言模型请求不消耗音频时长
+	_, err := limiter.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Len(t.sleeps, 2)
+}
+
+func (t *TestUnitRateLimiterSuite) TestMaxInFlight() {
+	release := make(chan struct{})
+	blocking := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		<-release
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+	})
+	limiter := NewRateLimiter(blocking, RateLimitConfig{MaxInFlight: 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = limiter.RecogniteLanguage(nil)
+		}()
+	}
+
+	t.Eventually(func() bool {
+		return limiter.InFlight() == 1 && limiter.QueueDepth() == 2
+	}, time.Second, 5*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	t.Equal(0, limiter.QueueDepth())
+	t.Equal(0, limiter.InFlight())
+	t.Equal(3, blocking.calls)
+}
+
+func (t *TestUnitRateLimiterSuite) TestContextCanceled() {
+	fake := okRecognizer()
+	limiter := t.fakeClock(NewRateLimiter(fake, RateLimitConfig{RequestsPerSecond: 1, RequestBurst: 1}))
+	_, err := limiter.RecogniteLanguage(nil)
+	t.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = limiter.RecogniteLanguageContext(ctx, nil)
+	t.ErrorIs(err, context.Canceled)
+	t.Equal(1, fake.calls)
+	t.Equal(0, limiter.QueueDepth())
+
+	// 取消的调用归还了预支的令牌，1秒后的调用不需要等待
+	t.now = t.now.Add(time.Second)
+	_, err = limiter.RecogniteLanguage(nil)
+	t.NoError(err)
+	t.Empty(t.sleeps)
+}
+
+func (t *TestUnitRateLimiterSuite) TestCanceledInFlightRefund() {
+	release := make(chan struct{})
+	blocking := newFakeRecognizer(func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		<-release
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+	})
+	limiter := t.fakeClock(NewRateLimiter(blocking, RateLimitConfig{
+		RequestsPerSecond:     1,
+		RequestBurst:          2,
+		AudioSecondsPerSecond: 1,
+		AudioBurst:            2,
+		MaxInFlight:           1,
+	}))
+	wavData := make([]byte, 16000*2)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = limiter.Recognite(wavData, 16000, 1, 2)
+	}()
+	t.Eventually(func() bool { return limiter.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	// 等待并发名额期间被取消的调用归还预支的令牌
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		t.Eventually(func() bool { return limiter.QueueDepth() == 1 }, time.Second, time.Millisecond)
+		cancel()
+	}()
+	_, err := limiter.RecogniteContext(ctx, wavData, 16000, 1, 2)
+	t.ErrorIs(err, context.Canceled)
+
+	close(release)
+	<-done
+	_, err = limiter.Recognite(wavData, 16000, 1, 2)
+	t.NoError(err)
+	t.Empty(t.sleeps)
+	t.Equal(2, blocking.calls)
+}
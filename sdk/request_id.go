@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// DefaultRequestIDHeader 默认携带请求ID的HTTP请求头，gRPC协议使用小写的 x-request-id 元数据
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// requestIDKey 在 context 中保存请求ID的键
+type requestIDKey struct{}
+
+// metadataKey 在 context 中保存用户元数据的键
+type metadataKey struct{}
+
+// requestIDCounter 无法读取随机数时用于生成请求ID的计数器
+var requestIDCounter uint64
+
+// ContextWithRequestID 为使用该 ctx 的请求指定请求ID，未指定时每次调用都会自动生成一个，
+// 同一次调用的所有重试使用相同的请求ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 获取 ctx 中的请求ID，未指定时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextWithMetadata 为使用该 ctx 的请求附加自定义元数据，HTTP协议以请求头的形式发送，
+// gRPC协议以元数据的形式发送，键会被转换为小写。多次调用时合并，后设置的值覆盖同名的键
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	parent := metadataFromContext(ctx)
+	merged := make(map[string]string, len(parent)+len(md))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range md {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// metadataFromContext 获取 ctx 中的用户元数据
+func metadataFromContext(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// WithRequestIDHeader 设置携带请求ID的请求头名称，服务端在同名的响应头中回传的请求ID会被读取到响应对象中，
+// 默认为 X-Request-ID
+func WithRequestIDHeader(header string) Option {
+	return func(o *options) {
+		o.requestIDHeader = header
+	}
+}
+
+// requestIDHeaderName 获取携带请求ID的请求头名称
+func (o options) requestIDHeaderName() string {
+	if o.requestIDHeader == "" {
+		return DefaultRequestIDHeader
+	}
+
+	return o.requestIDHeader
+}
+
+// requestMetadata 获取请求需要携带的请求ID和用户元数据
+func (o options) requestMetadata(ctx context.Context) map[string]string {
+	md := metadataFromContext(ctx)
+	headers := make(map[string]string, len(md)+1)
+	for k, v := range md {
+		headers[k] = v
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		headers[o.requestIDHeaderName()] = requestID
+	}
+
+	return headers
+}
+
+// newRequestID 生成一个随机的请求ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" +
+			strconv.FormatUint(atomic.AddUint64(&requestIDCounter, 1), 36)
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// ensureRequestID 获取 ctx 中的请求ID，未指定时生成一个新的请求ID并保存到返回的 ctx 中
+func ensureRequestID(ctx context.Context) (context.Context, string) {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return ctx, requestID
+	}
+
+	requestID := newRequestID()
+	return ContextWithRequestID(ctx, requestID), requestID
+}
+
+// tagRequestID 为响应对象或错误补充请求ID，服务端已经回传请求ID时保留服务端的值
+func tagRequestID(response *common.AsrtAPIResponse, err error, requestID string) {
+	if response != nil && response.RequestID == "" {
+		response.RequestID = requestID
+	}
+
+	var apiError *common.APIError
+	if errors.As(err, &apiError) && apiError.RequestID == "" {
+		apiError.RequestID = requestID
+		if apiError.Response != nil && apiError.Response.RequestID != "" {
+			apiError.RequestID = apiError.Response.RequestID
+		}
+	}
+	var transportError *common.TransportError
+	if errors.As(err, &transportError) && transportError.RequestID == "" {
+		transportError.RequestID = requestID
+	}
+}
@@ -0,0 +1,227 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitRequestID(t *testing.T) {
+	suite.Run(t, new(TestUnitRequestIDSuite))
+}
+
+type TestUnitRequestIDSuite struct {
+	suite.Suite
+	mutex   sync.Mutex
+	headers []http.Header
+}
+
+// start 启动记录请求头的测试用HTTP服务端，handler 为nil时返回固定的识别文本，返回端口
+func (t *TestUnitRequestIDSuite) start(handler http.HandlerFunc) string {
+	t.headers = nil
+	if handler == nil {
+		handler = fakeHTTPHandler
+	}
+	_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+		t.mutex.Lock()
+		t.headers = append(t.headers, r.Header.Clone())
+		t.mutex.Unlock()
+		handler(w, r)
+	})
+
+	return port
+}
+
+func (t *TestUnitRequestIDSuite) TestGeneratedRequestID() {
+	port := t.start(nil)
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Regexp(regexp.MustCompile(`^[0-9a-f]{32}$`), rsp.RequestID)
+	t.Equal(rsp.RequestID, t.headers[0].Get(DefaultRequestIDHeader))
+
+	other, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.NotEqual(rsp.RequestID, other.RequestID)
+}
+
+func (t *TestUnitRequestIDSuite) TestHTTPEchoAndMetadata() {
+	port := t.start(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", "server-"+r.Header.Get("X-Trace"))
+		w.Header().Add("Server-Timing", "queue;dur=2")
+		w.Header().Add("Server-Timing", `infer;dur=40;desc="model"`)
+		fakeHTTPHandler(w, r)
+	})
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithRequestIDHeader("X-Trace"))
+
+	ctx := ContextWithRequestID(context.Background(), "abc")
+	ctx = ContextWithMetadata(ctx, map[string]string{"X-Tenant": "a", "X-User": "u"})
+	ctx = ContextWithMetadata(ctx, map[string]string{"X-Tenant": "b"})
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("server-abc", rsp.RequestID)
+	t.Equal([]common.ServerTiming{
+		{Name: "queue", Duration: 2 * time.Millisecond},
+		{Name: "infer", Duration: 40 * time.Millisecond, Description: "model"},
+	}, rsp.ServerTiming)
+	t.Equal("abc", t.headers[0].Get("X-Trace"))
+	t.Equal("b", t.headers[0].Get("X-Tenant"))
+	t.Equal("u", t.headers[0].Get("X-User"))
+	t.Equal("", t.headers[0].Get(DefaultRequestIDHeader))
+}
+
+func (t *TestUnitRequestIDSuite) TestRetriesShareRequestID() {
+	first := true
+	port := t.start(func(w http.ResponseWriter, r *http.Request) {
+		if first {
+			first = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fakeHTTPHandler(w, r)
+	})
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", WithRetryPolicy(policy))
+
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Len(t.headers, 2)
+	t.Equal(rsp.RequestID, t.headers[0].Get(DefaultRequestIDHeader))
+	t.Equal(rsp.RequestID, t.headers[1].Get(DefaultRequestIDHeader))
+}
+
+func (t *TestUnitRequestIDSuite) TestErrorRequestID() {
+	port := t.start(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+	ctx := ContextWithRequestID(context.Background(), "failed-1")
+	_, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	var apiError *common.APIError
+	t.True(errors.As(err, &apiError))
+	t.Equal("failed-1", apiError.RequestID)
+	t.Contains(err.Error(), "failed-1")
+
+	closed := NewHTTPSpeechRecognizer("127.0.0.1", "1", "http", "")
+	_, err = closed.RecogniteLanguageContext(ctx, []string{"ni3"})
+	var transportError *common.TransportError
+	t.True(errors.As(err, &transportError))
+	t.Equal("failed-1", transportError.RequestID)
+}
+
+func (t *TestUnitRequestIDSuite) TestErrorEchoedRequestID() {
+	tests := []struct {
+		name       string
+		httpStatus int
+		body       string
+	}{
+		{name: "html error page", httpStatus: http.StatusBadGateway, body: "<html>bad gateway</html>"},
+		{name: "empty error body", httpStatus: http.StatusInternalServerError},
+		{name: "invalid ok body", httpStatus: http.StatusOK, body: "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			port := t.start(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(DefaultRequestIDHeader, "server-"+r.Header.Get(DefaultRequestIDHeader))
+				w.WriteHeader(tt.httpStatus)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+
+			ctx := ContextWithRequestID(context.Background(), "client-1")
+			_, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+			var apiError *common.APIError
+			t.True(errors.As(err, &apiError))
+			t.Equal("server-client-1", apiError.RequestID)
+		})
+	}
+}
+
+func (t *TestUnitRequestIDSuite) TestGRPCErrorRequestID() {
+	tests := []struct {
+		name string
+		send func(ctx context.Context, md metadata.MD) error
+	}{
+		{name: "header", send: grpc.SetHeader},
+		{name: "trailer", send: grpc.SetTrailer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			send := tt.send
+			interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+				handler grpc.UnaryHandler,
+			) (interface{}, error) {
+				_ = send(ctx, metadata.Pairs("x-request-id", "server-id"))
+				return nil, status.Error(codes.InvalidArgument, "bad samples")
+			}
+			port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+			recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+			defer recognizer.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := recognizer.RecogniteLanguageContext(ContextWithRequestID(ctx, "client-id"), []string{"ni3"})
+			var apiError *common.APIError
+			t.True(errors.As(err, &apiError))
+			t.Equal("server-id", apiError.RequestID)
+		})
+	}
+
+	// 服务端没有回传时使用客户端的请求ID
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "failed")
+	}
+	port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+	_, err := recognizer.RecogniteContext(ContextWithRequestID(context.Background(), "client-id"),
+		make([]byte, 3200), 16000, 1, 2)
+	var apiError *common.APIError
+	t.True(errors.As(err, &apiError))
+	t.Equal("client-id", apiError.RequestID)
+}
+
+func (t *TestUnitRequestIDSuite) TestGRPCMetadata() {
+	var incoming metadata.MD
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		incoming, _ = metadata.FromIncomingContext(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "server-id"))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("server-timing", "infer;dur=5"))
+		return handler(ctx, req)
+	}
+	port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc")
+	defer recognizer.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = ContextWithMetadata(ContextWithRequestID(ctx, "client-id"), map[string]string{"X-Tenant": "a"})
+
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal([]string{"client-id"}, incoming.Get("x-request-id"))
+	t.Equal([]string{"a"}, incoming.Get("x-tenant"))
+	t.Equal("server-id", rsp.RequestID)
+	t.Equal([]common.ServerTiming{{Name: "infer", Duration: 5 * time.Millisecond}}, rsp.ServerTiming)
+}
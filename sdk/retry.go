@@ -0,0 +1,218 @@
+package sdk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// RetryEvent 一次重试的相关信息，在重试等待开始前通过 RetryPolicy.OnRetry 回调
+type RetryEvent struct {
+	// Op 正在重试的操作，例如 "all"、"speech"、"language"
+	Op string
+	// Attempt 即将进行的是第几次尝试，从2开始
+	Attempt int
+	// Err 上一次尝试返回的错误
+	Err error
+	// Backoff 本次重试前的等待时间
+	Backoff time.Duration
+}
+
+// RetryPolicy 识别请求失败时的自动重试策略
+//
+// 等待时间按 InitialBackoff * Multiplier^(n-1) 指数增长并以 MaxBackoff 为上限，
+// 再按 Jitter 比例随机抖动。ctx 被取消，或其截止时间早于下一次重试开始的时间时不再重试
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试的次数，包括第一次请求，小于等于1时不重试
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间
+	InitialBackoff time.Duration
+	// MaxBackoff 单次等待时间的上限
+	MaxBackoff time.Duration
+	// Multiplier 每次重试后等待时间的增长倍数
+	Multiplier float64
+	// Jitter 等待时间的随机抖动比例，取值范围 [0, 1]，例如0.2表示在 ±20% 范围内抖动
+	Jitter float64
+	// RetryableStatusCodes 可重试的ASRT接口状态码
+	RetryableStatusCodes []int
+	// RetryableHTTPStatus 可重试的HTTP响应状态码，只用于响应体不是ASRT接口响应的情况
+	RetryableHTTPStatus []int
+	// RetryableGRPCCodes 可重试的gRPC状态码
+	RetryableGRPCCodes []codes.Code
+	// OnRetry 每次重试前的回调，可以用于统计重试次数，为nil时不回调
+	OnRetry func(event RetryEvent)
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+}
+
+// DefaultRetryPolicy 获取默认的重试策略：最多尝试3次，等待时间从100ms开始翻倍，上限5s，抖动20%，
+// 对服务端运行错误、HTTP 502/503/504、gRPC Unavailable/DeadlineExceeded 以及连接失败、单次尝试超时等传输错误重试，
+// 证书校验失败、域名不存在等重试也无法成功的传输错误不重试
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			common.APIStatusCodeServerError,
+			common.APIStatusCodeServerErrorRunning,
+		},
+		RetryableHTTPStatus: []int{502, 503, 504},
+		RetryableGRPCCodes:  []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+	}
+}
+
+// WithRetryPolicy 设置识别请求失败时的重试策略，默认不重试，policy 为nil时关闭重试
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// noRetryKey 标记不需要重试的请求的 context 键
+type noRetryKey struct{}
+
+// withoutRetry 标记 ctx 上的请求不按重试策略重试，例如健康检查
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// do 按重试策略调用 call，返回最后一次尝试的结果
+func (p *RetryPolicy) do(ctx context.Context, op string, logger common.Logger,
+	call func(ctx context.Context) (*common.AsrtAPIResponse, error),
+) (*common.AsrtAPIResponse, error) {
+	for attempt := 1; ; attempt += 1 {
+		rsp, err := call(ctx)
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(ctx, err) {
+			return rsp, err
+		}
+
+		backoff := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+			return rsp, err
+		}
+
+		logger.Warn("asrt request failed, retrying", common.F("op", op), common.F("attempt", attempt+1),
+			common.F("backoff", backoff), common.F("error", err))
+		if p.OnRetry != nil {
+			p.OnRetry(RetryEvent{Op: op, Attempt: attempt + 1, Err: err, Backoff: backoff})
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return rsp, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable 判断错误是否可以重试，调用方的 ctx 结束时不再重试，
+// WithTimeout 设置的单次尝试超时与其它传输错误一样按协议对应的规则判断
+func (p *RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.GRPCCode != codes.OK {
+			return p.retryableGRPCCode(apiErr.GRPCCode)
+		}
+		if apiErr.HTTPStatus != 0 && apiErr.Response == nil {
+			for _, httpStatus := range p.RetryableHTTPStatus {
+				if httpStatus == apiErr.HTTPStatus {
+					return true
+				}
+			}
+
+			return false
+		}
+		for _, statusCode := range p.RetryableStatusCodes {
+			if statusCode == apiErr.StatusCode {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var transportErr *common.TransportError
+	if errors.As(err, &transportErr) {
+		if s, ok := status.FromError(transportErr.Err); ok {
+			return p.retryableGRPCCode(s.Code())
+		}
+
+		return !permanentTransportError(transportErr.Err)
+	}
+
+	return false
+}
+
+// permanentTransportError 判断传输错误是否重试也无法成功，例如证书校验失败、
+// 与非TLS的服务端进行TLS握手或域名不存在
+func permanentTransportError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsNotFound
+	}
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var systemRootsErr x509.SystemRootsError
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &certificateInvalidErr) || errors.As(err, &systemRootsErr) ||
+		errors.As(err, &recordHeaderErr)
+}
+
+// retryableGRPCCode 判断gRPC状态码是否可以重试
+func (p *RetryPolicy) retryableGRPCCode(code codes.Code) bool {
+	for _, retryable := range p.RetryableGRPCCodes {
+		if retryable == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff 计算第 attempt 次尝试失败后的等待时间
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		p.randMutex.Lock()
+		if p.rand == nil {
+			p.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		backoff *= 1 + p.Jitter*(2*p.rand.Float64()-1)
+		p.randMutex.Unlock()
+	}
+
+	return time.Duration(backoff)
+}
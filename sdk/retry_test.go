@@ -0,0 +1,285 @@
+package sdk
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitRetry(t *testing.T) {
+	suite.Run(t, new(TestUnitRetrySuite))
+}
+
+type TestUnitRetrySuite struct {
+	suite.Suite
+}
+
+// failing 获取前 failures 次调用返回 err 之后返回成功的识别函数，calls 记录调用次数
+func failing(failures int, err error, calls *int) func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+	return func(ctx context.Context) (*common.AsrtAPIResponse, error) {
+		*calls += 1
+		if *calls <= failures {
+			return nil, err
+		}
+
+		return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK}, nil
+	}
+}
+
+// serverError 可以重试的服务端运行错误
+var serverError = &common.APIError{StatusCode: common.APIStatusCodeServerErrorRunning}
+
+func (t *TestUnitRetrySuite) TestBackoffGrowth() {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 350 * time.Millisecond, Multiplier: 2}
+	t.Equal(100*time.Millisecond, policy.backoff(1))
+	t.Equal(200*time.Millisecond, policy.backoff(2))
+	t.Equal(350*time.Millisecond, policy.backoff(3))
+	t.Equal(350*time.Millisecond, policy.backoff(10))
+
+	// 倍数小于1时等待时间不会缩短
+	policy = &RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 0.5}
+	t.Equal(100*time.Millisecond, policy.backoff(3))
+}
+
+func (t *TestUnitRetrySuite) TestJitterBounds() {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.2}
+	policy.rand = rand.New(rand.NewSource(1))
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i += 1 {
+		backoff := policy.backoff(2)
+		t.GreaterOrEqual(int64(backoff), int64(160*time.Millisecond))
+		t.LessOrEqual(int64(backoff), int64(240*time.Millisecond))
+		seen[backoff] = true
+	}
+	t.Greater(len(seen), 100)
+}
+
+func (t *TestUnitRetrySuite) TestOnRetry() {
+	var events []RetryEvent
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2,
+		RetryableStatusCodes: []int{common.APIStatusCodeServerErrorRunning},
+		OnRetry:              func(event RetryEvent) { events = append(events, event) },
+	}
+
+	calls := 0
+	rsp, err := policy.do(context.Background(), "all", common.NopLogger{}, failing(5, serverError, &calls))
+	t.Nil(rsp)
+	t.Equal(serverError, err)
+	t.Equal(3, calls)
+	t.Equal([]RetryEvent{
+		{Op: "all", Attempt: 2, Err: serverError, Backoff: time.Millisecond},
+		{Op: "all", Attempt: 3, Err: serverError, Backoff: 2 * time.Millisecond},
+	}, events)
+
+	calls, events = 0, nil
+	rsp, err = policy.do(context.Background(), "all", common.NopLogger{}, failing(1, serverError, &calls))
+	t.NoError(err)
+	t.NotNil(rsp)
+	t.Equal(2, calls)
+	t.Len(events, 1)
+}
+
+func (t *TestUnitRetrySuite) TestDeadline() {
+	retried := 0
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second,
+		RetryableStatusCodes: []int{common.APIStatusCodeServerErrorRunning},
+		OnRetry:              func(event RetryEvent) { retried += 1 },
+	}
+
+	// 截止时间早于下一次重试开始的时间时不再等待
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	calls := 0
+	start := time.Now()
+	_, err := policy.do(ctx, "all", common.NopLogger{}, failing(5, serverError, &calls))
+	t.Equal(serverError, err)
+	t.Equal(1, calls)
+	t.Equal(0, retried)
+	t.Less(int64(time.Since(start)), int64(500*time.Millisecond))
+
+	// 等待期间 ctx 被取消时立即返回
+	ctx, cancel = context.WithCancel(context.Background())
+	policy.OnRetry = func(event RetryEvent) { cancel() }
+	calls = 0
+	_, err = policy.do(ctx, "all", common.NopLogger{}, failing(5, serverError, &calls))
+	t.Equal(serverError, err)
+	t.Equal(1, calls)
+}
+
+func (t *TestUnitRetrySuite) TestRetryable() {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "server error", err: serverError, want: true},
+		{name: "wrapped server error", err: fmt.Errorf("error: wrapped: %w", serverError), want: true},
+		{name: "client error", err: &common.APIError{StatusCode: common.APIStatusCodeClientErrorFormat}},
+		{name: "partial result", err: &common.APIError{StatusCode: common.APIStatusCodePartOK}},
+		{name: "http 503", err: common.NewHTTPStatusError(503, nil), want: true},
+		{name: "http 500", err: common.NewHTTPStatusError(500, nil)},
+		{name: "http 404", err: common.NewHTTPStatusError(404, nil)},
+		{
+			name: "http 503 with asrt body",
+			err:  common.NewHTTPStatusError(503, &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeClientError}),
+		},
+		{
+			name: "grpc unavailable",
+			err:  &common.APIError{StatusCode: common.APIStatusCodeServerError, GRPCCode: codes.Unavailable},
+			want: true,
+		},
+		{
+			name: "grpc internal",
+			err:  &common.APIError{StatusCode: common.APIStatusCodeServerError, GRPCCode: codes.Internal},
+		},
+		{name: "grpc unavailable status", err: translateGRPCError("all", status.Error(codes.Unavailable, "")), want: true},
+		{name: "grpc deadline status", err: translateGRPCError("all", status.Error(codes.DeadlineExceeded, "")), want: true},
+		{name: "grpc exhausted status", err: translateGRPCError("all", status.Error(codes.ResourceExhausted, ""))},
+		{
+			name: "connection refused",
+			err:  &common.TransportError{Op: "all", Err: errors.New("connection refused")},
+			want: true,
+		},
+		{
+			name: "dns temporary",
+			err:  &common.TransportError{Op: "all", Err: &net.DNSError{Err: "timeout", IsTemporary: true}},
+			want: true,
+		},
+		{
+			name: "dns not found",
+			err:  &common.TransportError{Op: "all", Err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		},
+		{
+			name: "unknown authority",
+			err:  &common.TransportError{Op: "all", Err: fmt.Errorf("tls: %w", x509.UnknownAuthorityError{})},
+		},
+		{
+			name: "hostname mismatch",
+			err:  &common.TransportError{Op: "all", Err: x509.HostnameError{Certificate: &x509.Certificate{}, Host: "asr"}},
+		},
+		{name: "canceled", err: &common.TransportError{Op: "all", Err: context.Canceled}},
+		{name: "attempt deadline", err: &common.TransportError{Op: "all", Err: context.DeadlineExceeded}, want: true},
+		{
+			name: "http attempt timeout",
+			err: &common.TransportError{Op: "all", Err: &url.Error{Op: "Post", URL: "http://asr/all",
+				Err: context.DeadlineExceeded}},
+			want: true,
+		},
+		{name: "context deadline", ctx: expired, err: &common.TransportError{Op: "all", Err: context.DeadlineExceeded}},
+		{name: "context canceled", ctx: canceled, err: serverError},
+		{name: "auth", err: fmt.Errorf("error: token: %w", common.ErrAuth)},
+	}
+
+	policy := DefaultRetryPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			t.Equal(tt.want, policy.retryable(ctx, tt.err))
+		})
+	}
+}
+
+func (t *TestUnitRetrySuite) TestCertificateNotRetried() {
+	server := httptest.NewTLSServer(http.HandlerFunc(fakeHTTPHandler))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	retried := 0
+	policy := DefaultRetryPolicy()
+	policy.OnRetry = func(event RetryEvent) { retried += 1 }
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "", WithRetryPolicy(policy))
+
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.ErrorIs(err, common.ErrTransport)
+	t.Equal(0, retried)
+}
+
+func (t *TestUnitRetrySuite) TestAttemptTimeout() {
+	tests := []struct {
+		name  string
+		start func(handler func()) func(opts ...Option) ISpeechRecognizer
+	}{
+		{
+			name: "http",
+			start: func(handler func()) func(opts ...Option) ISpeechRecognizer {
+				_, port := startFakeHTTPServer(t.T(), func(w http.ResponseWriter, r *http.Request) {
+					handler()
+					fakeHTTPHandler(w, r)
+				})
+				return func(opts ...Option) ISpeechRecognizer {
+					return NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "", opts...)
+				}
+			},
+		},
+		{
+			name: "grpc",
+			start: func(handler func()) func(opts ...Option) ISpeechRecognizer {
+				interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+					next grpc.UnaryHandler,
+				) (interface{}, error) {
+					handler()
+					return next(ctx, req)
+				}
+				port := startFakeGRPCServer(t.T(), nil, grpc.UnaryInterceptor(interceptor))
+				return func(opts ...Option) ISpeechRecognizer {
+					recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", opts...)
+					t.T().Cleanup(recognizer.Close)
+					return recognizer
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func() {
+			// 第一次请求超过单次尝试的超时时间，重试的请求立即返回
+			var mutex sync.Mutex
+			requests := 0
+			recognizer := tt.start(func() {
+				mutex.Lock()
+				requests += 1
+				slow := requests == 1
+				mutex.Unlock()
+				if slow {
+					time.Sleep(500 * time.Millisecond)
+				}
+			})
+
+			retried := 0
+			policy := DefaultRetryPolicy()
+			policy.InitialBackoff = time.Millisecond
+			policy.OnRetry = func(event RetryEvent) { retried += 1 }
+			rsp, err := recognizer(WithTimeout(100*time.Millisecond), WithRetryPolicy(policy)).
+				RecogniteLanguage([]string{"ni3"})
+			t.NoError(err)
+			t.Equal("你好", rsp.Result)
+			t.Equal(1, retried)
+		})
+	}
+}
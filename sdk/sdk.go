@@ -22,13 +22,18 @@ package sdk
 
 import "strings"
 
-// GetSpeechRecognizer 获取一个语音识别调用类实例对象
-func GetSpeechRecognizer(host string, port string, protocol string) ISpeechRecognizer {
+// GetSpeechRecognizer 获取一个语音识别调用类实例对象，协议不支持或构造失败时返回nil，
+// 需要具体错误信息或更多配置时请使用 NewSpeechRecognizerFromURL
+func GetSpeechRecognizer(host string, port string, protocol string, opts ...Option) ISpeechRecognizer {
 	protocol = strings.ToLower(protocol)
 	if protocol == "http" || protocol == "https" {
-		return NewHTTPSpeechRecognizer(host, port, protocol, "")
+		if recognizer := NewHTTPSpeechRecognizer(host, port, protocol, "", opts...); recognizer != nil {
+			return recognizer
+		}
 	} else if protocol == "grpc" || protocol == "grpcs" {
-		return NewGRPCSpeechRecognizer(host, port, protocol)
+		if recognizer := NewGRPCSpeechRecognizer(host, port, protocol, opts...); recognizer != nil {
+			return recognizer
+		}
 	}
 
 	return nil
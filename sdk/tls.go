@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSConfig https和grpcs协议的TLS配置，零值表示使用系统根证书校验服务端证书
+type TLSConfig struct {
+	// CAFile 校验服务端证书使用的PEM格式CA证书文件
+	CAFile string
+	// CAPEM 校验服务端证书使用的PEM格式CA证书内容，可以与 CAFile 同时使用
+	CAPEM []byte
+	// CertFile mTLS客户端证书文件，需要与 KeyFile 同时设置
+	CertFile string
+	// KeyFile mTLS客户端私钥文件
+	KeyFile string
+	// CertPEM mTLS客户端证书内容，需要与 KeyPEM 同时设置
+	CertPEM []byte
+	// KeyPEM mTLS客户端私钥内容
+	KeyPEM []byte
+	// ServerName 覆盖校验服务端证书时使用的主机名，例如通过IP地址连接时指定证书中的域名
+	ServerName string
+	// MinVersion 允许的最低TLS版本，例如 tls.VersionTLS13，默认 tls.VersionTLS12
+	MinVersion uint16
+	// InsecureSkipVerify 不校验服务端证书，只应在测试环境使用
+	InsecureSkipVerify bool
+}
+
+// Build 根据配置加载证书并生成 *tls.Config
+func (c TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if c.CAFile != "" || len(c.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+		if c.CAFile != "" {
+			caPEM, err := ioutil.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("error: read ca file `%s` failed, %w", c.CAFile, err)
+			}
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("error: no certificate found in ca file `%s`", c.CAFile)
+			}
+		}
+		if len(c.CAPEM) > 0 && !pool.AppendCertsFromPEM(c.CAPEM) {
+			return nil, fmt.Errorf("error: no certificate found in ca pem")
+		}
+		config.RootCAs = pool
+	}
+
+	switch {
+	case c.CertFile != "" || c.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error: load client certificate failed, %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	case len(c.CertPEM) > 0 || len(c.KeyPEM) > 0:
+		cert, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("error: load client certificate failed, %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// WithTLS 设置https和grpcs协议的TLS配置，证书在构造语音识别实例时加载，加载失败时构造函数返回nil
+func WithTLS(config TLSConfig) Option {
+	return func(o *options) {
+		o.tls = &config
+		o.tlsConfig = nil
+	}
+}
+
+// WithTLSConfig 直接使用给定的 *tls.Config 作为https和grpcs协议的TLS配置
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = nil
+		o.tlsConfig = config
+	}
+}
+
+// clientTLSConfig 获取实际使用的TLS配置，未设置时返回nil
+func (o options) clientTLSConfig() (*tls.Config, error) {
+	if o.tlsConfig != nil {
+		return o.tlsConfig.Clone(), nil
+	}
+	if o.tls != nil {
+		return o.tls.Build()
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,218 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// testServerName 测试证书中的服务端域名
+const testServerName = "asrt.test"
+
+// testPKI 测试时生成的自签名CA、服务端证书和客户端证书
+type testPKI struct {
+	caPEM      []byte
+	caPool     *x509.CertPool
+	serverCert tls.Certificate
+	clientPEM  []byte
+	clientKey  []byte
+}
+
+// newTestPKI 生成测试用的证书，服务端证书只对 testServerName 有效
+func newTestPKI(t *testing.T) *testPKI {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "asrt test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage, dnsNames []string) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "asrt test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			DNSNames:     dnsNames,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	serverPEM, serverKey := issue(2, x509.ExtKeyUsageServerAuth, []string{testServerName})
+	serverCert, err := tls.X509KeyPair(serverPEM, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPEM, clientKey := issue(3, x509.ExtKeyUsageClientAuth, nil)
+
+	pki := &testPKI{
+		caPEM:      pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		caPool:     x509.NewCertPool(),
+		serverCert: serverCert,
+		clientPEM:  clientPEM,
+		clientKey:  clientKey,
+	}
+	pki.caPool.AddCert(caCert)
+
+	return pki
+}
+
+// serverConfig 生成服务端TLS配置，mutual 为true时要求客户端证书
+func (p *testPKI) serverConfig(mutual bool) *tls.Config {
+	config := &tls.Config{
+		Certificates: []tls.Certificate{p.serverCert},
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+	}
+	if mutual {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = p.caPool
+	}
+
+	return config
+}
+
+func TestUnitTLS(t *testing.T) {
+	suite.Run(t, new(TestUnitTLSSuite))
+}
+
+type TestUnitTLSSuite struct {
+	suite.Suite
+	pki *testPKI
+}
+
+func (t *TestUnitTLSSuite) SetupSuite() {
+	t.pki = newTestPKI(t.T())
+}
+
+// startHTTPS 启动测试用HTTPS服务端，返回端口
+func (t *TestUnitTLSSuite) startHTTPS(mutual bool) string {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(fakeHTTPHandler))
+	server.TLS = t.pki.serverConfig(mutual)
+	server.StartTLS()
+	t.T().Cleanup(server.Close)
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	return port
+}
+
+func (t *TestUnitTLSSuite) TestHTTPS() {
+	port := t.startHTTPS(false)
+
+	// 未配置CA时无法校验自签名证书
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "")
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.Error(err)
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "",
+		WithTLS(TLSConfig{CAPEM: t.pki.caPEM, ServerName: testServerName}))
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+
+	// 服务端最高只支持TLS 1.2
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "",
+		WithTLS(TLSConfig{CAPEM: t.pki.caPEM, ServerName: testServerName, MinVersion: tls.VersionTLS13}))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.Error(err)
+}
+
+func (t *TestUnitTLSSuite) TestHTTPSMutual() {
+	port := t.startHTTPS(true)
+	dir := t.T().TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	t.NoError(ioutil.WriteFile(caFile, t.pki.caPEM, 0o600))
+	t.NoError(ioutil.WriteFile(certFile, t.pki.clientPEM, 0o600))
+	t.NoError(ioutil.WriteFile(keyFile, t.pki.clientKey, 0o600))
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "",
+		WithTLS(TLSConfig{CAFile: caFile, ServerName: testServerName}))
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.Error(err)
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "",
+		WithTLS(TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: testServerName}))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "",
+		WithTLS(TLSConfig{CAFile: filepath.Join(dir, "missing.pem")})))
+}
+
+func (t *TestUnitTLSSuite) TestGRPCS() {
+	port := startFakeGRPCServer(t.T(), nil, grpc.Creds(credentials.NewTLS(t.pki.serverConfig(true))))
+
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpcs",
+		WithTLS(TLSConfig{CAPEM: t.pki.caPEM, ServerName: testServerName}))
+	t.NotNil(recognizer)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.Error(err)
+	recognizer.Close()
+
+	recognizer = NewGRPCSpeechRecognizer("127.0.0.1", port, "grpcs", WithTLS(TLSConfig{
+		CAPEM:      t.pki.caPEM,
+		CertPEM:    t.pki.clientPEM,
+		KeyPEM:     t.pki.clientKey,
+		ServerName: testServerName,
+	}))
+	defer recognizer.Close()
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+}
+
+func (t *TestUnitTLSSuite) TestGRPCSDefault() {
+	// 未设置TLS配置时使用系统根证书，构造时不应失败
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", "20002", "grpcs")
+	t.NotNil(recognizer)
+	recognizer.Close()
+}
@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// defaultMaxIdleConns 默认最多保持的空闲连接数
+	defaultMaxIdleConns = 100
+	// defaultMaxIdleConnsPerHost 默认每个服务端最多保持的空闲连接数
+	defaultMaxIdleConnsPerHost = 16
+	// defaultIdleConnTimeout 空闲连接默认保持的时长
+	defaultIdleConnTimeout = 90 * time.Second
+	// defaultHTTPClientTimeout HTTP客户端的整体超时时间
+	defaultHTTPClientTimeout = 30 * time.Second
+)
+
+// HTTPTransportConfig HTTP协议的连接池配置，零值表示使用默认值
+//
+// 每个 HTTPSpeechRecognizer 实例持有一个连接池，在所有请求之间复用连接，
+// 避免高负载时反复建立连接和TLS握手，因此应当复用实例而不是每次请求都重新构造
+type HTTPTransportConfig struct {
+	// MaxIdleConns 最多保持的空闲连接数，默认100
+	MaxIdleConns int
+	// MaxIdleConnsPerHost 每个服务端最多保持的空闲连接数，默认16，应不小于常见的并发请求数
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个服务端最多同时建立的连接数，默认不限制
+	MaxConnsPerHost int
+	// IdleConnTimeout 空闲连接保持的时长，默认90s
+	IdleConnTimeout time.Duration
+	// HTTP2 使用HTTP/2协议在一个连接上多路复用请求，https协议通过ALPN协商，
+	// http协议直接使用明文HTTP/2 (h2c)，需要服务端或网关支持，h2c不支持通过代理连接
+	HTTP2 bool
+}
+
+// WithHTTPTransport 设置HTTP协议的连接池配置，默认只使用HTTP/1.1
+func WithHTTPTransport(config HTTPTransportConfig) Option {
+	return func(o *options) {
+		o.httpTransport = config
+	}
+}
+
+// newHTTPClient 根据配置构造HTTP协议实例使用的客户端
+func (o options) newHTTPClient(protocol string) (*http.Client, error) {
+	tlsConfig, err := o.clientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := o.httpProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	config := o.httpTransport
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if config.HTTP2 && protocol == "http" {
+		if o.proxySet && o.proxy != nil {
+			return nil, fmt.Errorf("error: h2c can not be used with a proxy")
+		}
+		return &http.Client{
+			Timeout: defaultHTTPClientTimeout,
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network string, address string, _ *tls.Config) (net.Conn, error) {
+					return dialer.Dial(network, address)
+				},
+			},
+		}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	transport.MaxIdleConns = defaultInt(config.MaxIdleConns, defaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = defaultInt(config.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost)
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+	transport.ForceAttemptHTTP2 = config.HTTP2
+	if !config.HTTP2 {
+		// 非nil的空映射表示禁止通过ALPN升级到HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return &http.Client{
+		Timeout:   defaultHTTPClientTimeout,
+		Transport: transport,
+	}, nil
+}
+
+// defaultInt value 不大于0时返回 defaultValue
+func defaultInt(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// KeepaliveConfig gRPC协议的连接保活配置
+//
+// 长时间空闲的连接可能被NAT网关或负载均衡器静默丢弃，开启保活后客户端会定期发送HTTP/2 PING，
+// 及时发现失效的连接。服务端的 keepalive.EnforcementPolicy 需要允许相应的PING频率，
+// 否则服务端会以 too_many_pings 为由关闭连接
+type KeepaliveConfig struct {
+	// Time 连接空闲该时长后发送PING，gRPC要求不小于10s
+	Time time.Duration
+	// Timeout 等待PING响应的时长，超时后关闭连接，默认20s
+	Timeout time.Duration
+	// PermitWithoutStream 没有进行中的请求时也发送PING
+	PermitWithoutStream bool
+}
+
+// WithKeepalive 设置gRPC协议的连接保活配置，默认不发送PING
+func WithKeepalive(config KeepaliveConfig) Option {
+	return func(o *options) {
+		o.keepalive = &config
+	}
+}
+
+// grpcKeepaliveOption 获取gRPC连接保活的拨号配置项，未设置时返回nil
+func (o options) grpcKeepaliveOption() (grpc.DialOption, error) {
+	if o.keepalive == nil {
+		return nil, nil
+	}
+	if o.keepalive.Time < 10*time.Second || o.keepalive.Timeout < 0 {
+		return nil, fmt.Errorf("error: invalid keepalive time `%s` or timeout `%s`, time must be at least 10s",
+			o.keepalive.Time, o.keepalive.Timeout)
+	}
+
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                o.keepalive.Time,
+		Timeout:             o.keepalive.Timeout,
+		PermitWithoutStream: o.keepalive.PermitWithoutStream,
+	}), nil
+}
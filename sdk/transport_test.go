@@ -0,0 +1,132 @@
+package sdk
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestUnitTransport(t *testing.T) {
+	suite.Run(t, new(TestUnitTransportSuite))
+}
+
+type TestUnitTransportSuite struct {
+	suite.Suite
+}
+
+// protoRecorder 记录最近一次请求使用的HTTP协议主版本号的测试用HTTP接口
+type protoRecorder struct {
+	protoMajor int32
+}
+
+func (p *protoRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.StoreInt32(&p.protoMajor, int32(r.ProtoMajor))
+	fakeHTTPHandler(w, r)
+}
+
+func (p *protoRecorder) proto() int {
+	return int(atomic.LoadInt32(&p.protoMajor))
+}
+
+func (t *TestUnitTransportSuite) TestConnectionReuse() {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(fakeHTTPHandler))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	t.T().Cleanup(server.Close)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "")
+	for i := 0; i < 20; i += 1 {
+		_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+		t.NoError(err)
+	}
+	t.Equal(int32(1), atomic.LoadInt32(&conns))
+
+	// 并发请求数不超过每个服务端的空闲连接数时，之后的请求复用已有的连接
+	for round := 0; round < 5; round += 1 {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i += 1 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = recognizer.RecogniteLanguage([]string{"ni3"})
+			}()
+		}
+		wg.Wait()
+	}
+	t.LessOrEqual(atomic.LoadInt32(&conns), int32(9))
+
+	recognizer.CloseIdleConnections()
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+}
+
+func (t *TestUnitTransportSuite) TestHTTP2OverTLS() {
+	recorder := &protoRecorder{}
+	server := httptest.NewUnstartedServer(recorder)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.T().Cleanup(server.Close)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	insecure := WithTLS(TLSConfig{InsecureSkipVerify: true})
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "", insecure)
+	_, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal(1, recorder.proto())
+
+	recognizer = NewHTTPSpeechRecognizer("127.0.0.1", port, "https", "", insecure,
+		WithHTTPTransport(HTTPTransportConfig{HTTP2: true}))
+	_, err = recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal(2, recorder.proto())
+}
+
+func (t *TestUnitTransportSuite) TestH2C() {
+	recorder := &protoRecorder{}
+	server := httptest.NewServer(h2c.NewHandler(recorder, &http2.Server{}))
+	t.T().Cleanup(server.Close)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	recognizer := NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithHTTPTransport(HTTPTransportConfig{HTTP2: true}))
+	rsp, err := recognizer.RecogniteLanguage([]string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(2, recorder.proto())
+
+	t.Nil(NewHTTPSpeechRecognizer("127.0.0.1", port, "http", "",
+		WithHTTPTransport(HTTPTransportConfig{HTTP2: true}),
+		WithProxy(&url.URL{Scheme: "http", Host: "proxy:3128"})))
+}
+
+func (t *TestUnitTransportSuite) TestGRPCKeepalive() {
+	port := startFakeGRPCServer(t.T(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recognizer := NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc",
+		WithKeepalive(KeepaliveConfig{Time: time.Minute, Timeout: 10 * time.Second}))
+	t.NotNil(recognizer)
+	defer recognizer.Close()
+	rsp, err := recognizer.RecogniteLanguageContext(ctx, []string{"ni3"})
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+
+	t.Nil(NewGRPCSpeechRecognizer("127.0.0.1", port, "grpc", WithKeepalive(KeepaliveConfig{Time: time.Second})))
+}
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"context"
+	"io"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+// contextRecognizer 包装类需要实现的基本识别操作，其余方法由 recognizerMixin 基于这些操作实现
+type contextRecognizer interface {
+	RecogniteContext(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+	) (*common.AsrtAPIResponse, error)
+	RecogniteSpeechContext(ctx context.Context, wavData []byte, frameRate int, channels int, byteWidth int,
+	) (*common.AsrtAPIResponse, error)
+	RecogniteLanguageContext(ctx context.Context, sequencePinyin []string) (*common.AsrtAPIResponse, error)
+}
+
+// recognizerMixin 为包装其他 ISpeechRecognizer 的类型补全 ContextSpeechRecognizer 接口的其余方法，
+// 长音频识别会按片段调用包装类自身的 RecogniteContext，使每个片段都经过包装逻辑
+type recognizerMixin struct {
+	self contextRecognizer
+}
+
+// Recognite 调用ASRT语音识别
+func (m recognizerMixin) Recognite(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return m.self.RecogniteContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteSpeech 调用ASRT语音识别声学模型
+func (m recognizerMixin) RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return m.self.RecogniteSpeechContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLanguage 调用ASRT语音识别语言模型
+func (m recognizerMixin) RecogniteLanguage(sequencePinyin []string) (*common.AsrtAPIResponse, error) {
+	return m.self.RecogniteLanguageContext(context.Background(), sequencePinyin)
+}
+
+// RecogniteLong 调用ASRT语音识别来识别长音频序列
+func (m recognizerMixin) RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int,
+) ([]*common.AsrtAPIResponse, error) {
+	return m.RecogniteLongContext(context.Background(), wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLongContext 调用ASRT语音识别来识别长音频序列，可以通过 ctx 控制超时和取消
+func (m recognizerMixin) RecogniteLongContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteLong(ctx, m.self.RecogniteContext, wavData, frameRate, channels, byteWidth, opts...)
+}
+
+// RecogniteFile 调用ASRT语音识别来识别指定文件名的音频文件
+func (m recognizerMixin) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
+	return m.RecogniteFileContext(context.Background(), filename)
+}
+
+// RecogniteFileContext 调用ASRT语音识别来识别指定文件名的音频文件，可以通过 ctx 控制超时和取消
+func (m recognizerMixin) RecogniteFileContext(ctx context.Context, filename string,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteFile(ctx, m.self.RecogniteContext, filename, opts...)
+}
+
+// withContext 将 ISpeechRecognizer 转换为 ContextSpeechRecognizer，
+// 未实现 ContextSpeechRecognizer 的外部实现由 contextAdapter 包装
+func withContext(r ISpeechRecognizer) ContextSpeechRecognizer {
+	if cr, ok := r.(ContextSpeechRecognizer); ok {
+		return cr
+	}
+
+	return contextAdapter{r}
+}
+
+// contextAdapter 为只实现了 ISpeechRecognizer 的识别类补全带 ctx 的方法，
+// 被包装的方法无法中途取消，只在调用前检查 ctx 是否已经结束
+type contextAdapter struct {
+	ISpeechRecognizer
+}
+
+// RecogniteContext 调用ASRT语音识别，ctx 已经结束时直接返回错误
+func (a contextAdapter) RecogniteContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Recognite(wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteSpeechContext 调用ASRT语音识别声学模型，ctx 已经结束时直接返回错误
+func (a contextAdapter) RecogniteSpeechContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.RecogniteSpeech(wavData, frameRate, channels, byteWidth)
+}
+
+// RecogniteLanguageContext 调用ASRT语音识别语言模型，ctx 已经结束时直接返回错误
+func (a contextAdapter) RecogniteLanguageContext(ctx context.Context, sequencePinyin []string,
+) (*common.AsrtAPIResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.RecogniteLanguage(sequencePinyin)
+}
+
+// RecogniteLongContext 按片段调用被包装类的 Recognite 识别长音频序列，每个片段前检查 ctx
+func (a contextAdapter) RecogniteLongContext(ctx context.Context, wavData []byte,
+	frameRate int, channels int, byteWidth int, opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteLong(ctx, a.RecogniteContext, wavData, frameRate, channels, byteWidth, opts...)
+}
+
+// RecogniteFileContext 按片段调用被包装类的 Recognite 识别指定文件名的音频文件，每个片段前检查 ctx
+func (a contextAdapter) RecogniteFileContext(ctx context.Context, filename string,
+	opts ...RecogniteLongOption,
+) ([]*common.AsrtAPIResponse, error) {
+	return recogniteFile(ctx, a.RecogniteContext, filename, opts...)
+}
+
+// closeRecognizer 释放识别实例持有的连接：实现了 io.Closer 的实例调用其 Close，
+// GRPCSpeechRecognizer 关闭gRPC连接，HTTPSpeechRecognizer 关闭空闲连接，其他实例不做任何操作
+func closeRecognizer(r ISpeechRecognizer) error {
+	if adapter, ok := r.(contextAdapter); ok {
+		r = adapter.ISpeechRecognizer
+	}
+	switch closer := r.(type) {
+	case io.Closer:
+		return closer.Close()
+	case interface{ Close() }:
+		closer.Close()
+	case interface{ CloseIdleConnections() }:
+		closer.CloseIdleConnections()
+	}
+
+	return nil
+}
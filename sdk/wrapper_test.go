@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/nl8590687/asrt-sdk-go/common"
+)
+
+func TestUnitWrapper(t *testing.T) {
+	suite.Run(t, new(TestUnitWrapperSuite))
+}
+
+type TestUnitWrapperSuite struct {
+	suite.Suite
+}
+
+// legacyRecognizer 只实现了 ISpeechRecognizer 的外部识别类
+type legacyRecognizer struct {
+	calls int
+}
+
+func (l *legacyRecognizer) Recognite(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	l.calls += 1
+	return &common.AsrtAPIResponse{StatusCode: common.APIStatusCodeOK, Result: "你好"}, nil
+}
+
+func (l *legacyRecognizer) RecogniteSpeech(wavData []byte, frameRate int, channels int, byteWidth int,
+) (*common.AsrtAPIResponse, error) {
+	return l.Recognite(wavData, frameRate, channels, byteWidth)
+}
+
+func (l *legacyRecognizer) RecogniteLanguage(sequencePinyin []string) (*common.AsrtAPIResponse, error) {
+	return l.Recognite(nil, 16000, 1, 2)
+}
+
+func (l *legacyRecognizer) RecogniteLong(wavData []byte, frameRate int, channels int, byteWidth int,
+) ([]*common.AsrtAPIResponse, error) {
+	rsp, err := l.Recognite(wavData, frameRate, channels, byteWidth)
+	return []*common.AsrtAPIResponse{rsp}, err
+}
+
+func (l *legacyRecognizer) RecogniteFile(filename string) ([]*common.AsrtAPIResponse, error) {
+	return l.RecogniteLong(nil, 16000, 1, 2)
+}
+
+func (t *TestUnitWrapperSuite) TestContextSpeechRecognizer() {
+	recognizers := []ISpeechRecognizer{
+		NewHTTPSpeechRecognizer("127.0.0.1", "20001", "http", ""),
+		NewGRPCSpeechRecognizer("127.0.0.1", "20002", "grpc"),
+		NewCircuitBreaker(okRecognizer(), CircuitBreakerConfig{}),
+		NewRateLimiter(okRecognizer(), RateLimitConfig{}),
+		NewPreprocessor(okRecognizer()),
+		NewFailover(okRecognizer(), okRecognizer(), FailoverConfig{}),
+	}
+	for _, recognizer := range recognizers {
+		_, ok := recognizer.(ContextSpeechRecognizer)
+		t.True(ok, "%T", recognizer)
+	}
+}
+
+func (t *TestUnitWrapperSuite) TestLegacyRecognizer() {
+	legacy := &legacyRecognizer{}
+	breaker := NewCircuitBreaker(legacy, CircuitBreakerConfig{})
+
+	rsp, err := breaker.RecogniteContext(context.Background(), make([]byte, 3200), 16000, 1, 2)
+	t.NoError(err)
+	t.Equal("你好", rsp.Result)
+	t.Equal(1, legacy.calls)
+
+	// 外部实现无法中途取消，ctx 已经结束时不再调用
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = breaker.RecogniteContext(ctx, make([]byte, 3200), 16000, 1, 2)
+	t.ErrorIs(err, context.Canceled)
+	t.Equal(1, legacy.calls)
+
+	// 长音频按片段调用外部实现的 Recognite，可选配置项同样生效
+	results, err := breaker.RecogniteLongContext(context.Background(), make([]byte, 2*10*16000*2), 16000, 1, 2,
+		WithDTMFMasking())
+	t.NoError(err)
+	t.Len(results, 2)
+	t.Equal(3, legacy.calls)
+}
+
+// closableRecognizer 实现了 io.Closer 的外部识别类
+type closableRecognizer struct {
+	legacyRecognizer
+	closed bool
+}
+
+func (c *closableRecognizer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (t *TestUnitWrapperSuite) TestCloseRecognizer() {
+	grpcRecognizer := NewGRPCSpeechRecognizer("127.0.0.1", "20002", "grpc")
+	t.NoError(closeRecognizer(grpcRecognizer))
+	t.Equal(connectivity.Shutdown, grpcRecognizer.connection.GetState())
+
+	closable := &closableRecognizer{}
+	t.NoError(closeRecognizer(withContext(closable)))
+	t.True(closable.closed)
+
+	t.NoError(closeRecognizer(NewHTTPSpeechRecognizer("127.0.0.1", "20001", "http", "")))
+	t.NoError(closeRecognizer(&legacyRecognizer{}))
+}